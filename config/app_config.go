@@ -12,20 +12,24 @@ type AppConfig struct {
 }
 
 var _appConfig *AppConfig = nil
+var _appConfigErr error = nil
 
-// GetAppConfig ...
-func GetAppConfig() AppConfig {
+// GetAppConfig - the app config, and the error if the home directory could not be found,
+// in which case HomeDir is empty
+func GetAppConfig() (AppConfig, error) {
 	if _appConfig == nil {
-		_appConfig = loadAppConfig()
+		_appConfig, _appConfigErr = loadAppConfig()
 	}
-	return *_appConfig
+	return *_appConfig, _appConfigErr
 }
 
-func loadAppConfig() *AppConfig {
+func loadAppConfig() (*AppConfig, error) {
 	d, err := os.UserHomeDir()
-	util.Check(err)
+	if err != nil {
+		return &AppConfig{}, util.FileError(err, "could not find the home directory")
+	}
 
 	return &AppConfig{
 		HomeDir: d,
-	}
+	}, nil
 }
@@ -3,9 +3,9 @@ package config
 import (
 	"encoding/json"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/thomgray/notebee/util"
@@ -14,6 +14,11 @@ import (
 // Conf ...
 type Conf struct {
 	DefaultRoot *string
+	LineNumbers bool
+	NumberLinks bool
+	ShowTOC     bool
+	// Opener - the command external links are opened with, e.g. "firefox --new-tab"
+	Opener string
 }
 
 // Config ...
@@ -24,30 +29,35 @@ type Config struct {
 	// NotePaths   []string
 }
 
-// MakeConfig ...
-func MakeConfig() *Config {
-	return (&Config{}).Init()
+// MakeConfig - the config loaded from ~/.notebee, and the error if any of it could not be read.
+// What can't be read is left at its default
+func MakeConfig() (*Config, error) {
+	c := &Config{}
+	if _, err := GetAppConfig(); err != nil {
+		return c, err
+	}
+	return c, c.Init()
 }
 
-// Init ...
-func (c *Config) Init() *Config {
-	c.SearchPaths = loadSeachPaths()
+// Init - (re)load the config files
+func (c *Config) Init() error {
+	paths, err := loadSeachPaths()
+	c.SearchPaths = paths
 	confFileP := FilePath()
 
-	if _, err := os.Stat(confFileP); err == nil {
-		confBytes, err2 := ioutil.ReadFile(confFileP)
-		if err2 == nil {
-			var conf Conf
-			json.Unmarshal(confBytes, &conf)
-			c.conf = conf
-			c.currentDocRoot = conf.DefaultRoot
-		} else {
-			log.Panicln(err2)
-		}
-	} else {
-		// log.Panicln(err)
+	confBytes, err2 := ioutil.ReadFile(confFileP)
+	if os.IsNotExist(err2) {
+		return err
+	} else if err2 != nil {
+		return util.FileError(err2, "could not read %s", confFileP)
+	}
+	var conf Conf
+	if err2 := json.Unmarshal(confBytes, &conf); err2 != nil {
+		return util.FileError(err2, "%s is not valid JSON", confFileP)
 	}
-	return c
+	c.conf = conf
+	c.currentDocRoot = conf.DefaultRoot
+	return err
 }
 
 // SetCurrentDocRoot ...
@@ -56,9 +66,54 @@ func (c *Config) SetCurrentDocRoot(p string) {
 }
 
 // SetDefaultDocRoot ...
-func (c *Config) SetDefaultDocRoot(p string) {
+func (c *Config) SetDefaultDocRoot(p string) error {
 	c.conf.DefaultRoot = &p
-	c.writeConfig()
+	return c.writeConfig()
+}
+
+// LineNumbers - whether code blocks are rendered with line numbers
+func (c *Config) LineNumbers() bool {
+	return c.conf.LineNumbers
+}
+
+// SetLineNumbers - turn line numbers in code blocks on or off, and save this as the default
+func (c *Config) SetLineNumbers(on bool) error {
+	c.conf.LineNumbers = on
+	return c.writeConfig()
+}
+
+// NumberLinks - whether links are rendered numbered, with their hrefs listed at the end
+func (c *Config) NumberLinks() bool {
+	return c.conf.NumberLinks
+}
+
+// SetNumberLinks - turn numbered links on or off, and save this as the default
+func (c *Config) SetNumberLinks(on bool) error {
+	c.conf.NumberLinks = on
+	return c.writeConfig()
+}
+
+// ShowTOC - whether the table of contents is shown beside notes
+func (c *Config) ShowTOC() bool {
+	return c.conf.ShowTOC
+}
+
+// SetShowTOC - show or hide the table of contents, and save this as the default
+func (c *Config) SetShowTOC(on bool) error {
+	c.conf.ShowTOC = on
+	return c.writeConfig()
+}
+
+// Opener - the command to open external links with, split into its arguments.
+// Defaults to the system's opener
+func (c *Config) Opener() []string {
+	if args := strings.Fields(c.conf.Opener); len(args) > 0 {
+		return args
+	}
+	if runtime.GOOS == "darwin" {
+		return []string{"open"}
+	}
+	return []string{"xdg-open"}
 }
 
 // DocumentRoot ...
@@ -66,17 +121,40 @@ func (c *Config) DocumentRoot() *string {
 	return c.currentDocRoot
 }
 
-func loadSeachPaths() []string {
-	bytes, _ := util.ReadFile(NotePathsPath())
+// Roots - all directories notes are loaded from, in order of precedence:
+// the document root first, then the search paths in the order they were added
+func (c *Config) Roots() []string {
+	roots := make([]string, 0)
+	if c.currentDocRoot != nil {
+		roots = append(roots, filepath.Clean(*c.currentDocRoot))
+	}
+	for _, sp := range c.SearchPaths {
+		sp = filepath.Clean(sp)
+		if !util.StringSliceContains(roots, sp) {
+			roots = append(roots, sp)
+		}
+	}
+	return roots
+}
+
+func loadSeachPaths() ([]string, error) {
+	bytes, err := util.ReadFile(NotePathsPath())
+	if err != nil && !os.IsNotExist(err) {
+		return []string{}, util.FileError(err, "could not read %s", NotePathsPath())
+	}
 	paths := util.ReadLines(bytes)
-	return paths
+	return paths, nil
 }
 
-func (c *Config) writeConfig() {
+func (c *Config) writeConfig() error {
 	serlaised, err := json.Marshal(c.conf)
 	if err == nil {
-		ioutil.WriteFile(FilePath(), serlaised, 0644)
+		err = ioutil.WriteFile(FilePath(), serlaised, 0644)
 	}
+	if err != nil {
+		return util.FileError(err, "could not save %s", FilePath())
+	}
+	return nil
 }
 
 // func loadNotePaths(searchPaths []string) []string {
@@ -100,7 +178,9 @@ var _homedir *string = nil
 
 // Directory ...
 func Directory() string {
-	return filepath.Join(GetAppConfig().HomeDir, ".notebee")
+	// MakeConfig reports it if the home directory can't be found
+	ac, _ := GetAppConfig()
+	return filepath.Join(ac.HomeDir, ".notebee")
 }
 
 // FilePath ...
@@ -113,26 +193,44 @@ func NotePathsPath() string {
 	return filepath.Join(Directory(), "paths")
 }
 
+// HistoryPath - where the navigation history is saved between sessions
+func HistoryPath() string {
+	return filepath.Join(Directory(), "history")
+}
+
+// BookmarksPath - where bookmarks are saved, as JSON
+func BookmarksPath() string {
+	return filepath.Join(Directory(), "bookmarks")
+}
+
+// KeysPath - where key bindings are configured, as JSON
+func KeysPath() string {
+	return filepath.Join(Directory(), "keys")
+}
+
 // AddSearchPath ...
-func (c *Config) AddSearchPath(sp string) {
+func (c *Config) AddSearchPath(sp string) error {
 	c.SearchPaths = append(c.SearchPaths, sp)
-	c.updateSearchPathConfig()
+	return c.updateSearchPathConfig()
 }
 
-func (c *Config) updateSearchPathConfig() {
+func (c *Config) updateSearchPathConfig() error {
 	serlaised := []byte(strings.Join(c.SearchPaths, "\n"))
-	ioutil.WriteFile(NotePathsPath(), serlaised, 0644)
+	if err := ioutil.WriteFile(NotePathsPath(), serlaised, 0644); err != nil {
+		return util.FileError(err, "could not save %s", NotePathsPath())
+	}
+	return nil
 }
 
 // RemoveSearchPath ...
-func (c *Config) RemoveSearchPath(sp string) {
+func (c *Config) RemoveSearchPath(sp string) error {
 	for i, p := range c.SearchPaths {
 		if p == sp {
 			newSp := append(c.SearchPaths[:i], c.SearchPaths[i+1:]...)
 			c.SearchPaths = newSp
 		}
 	}
-	c.updateSearchPathConfig()
+	return c.updateSearchPathConfig()
 }
 
 // ReloadNotes ...
@@ -6,6 +6,7 @@ const (
 	InputModeTraverse InputMode = iota
 	InputModeSearch
 	InputModeCommand
+	InputModeFind
 )
 
 type ActiveMode uint8
@@ -14,4 +15,17 @@ const (
 	ActiveModeDefault ActiveMode = iota
 	ActiveModeAutocomplete
 	ActiveModeSearchResultSelect
+	ActiveModeLinkSelect
+	ActiveModeFind
+	ActiveModeOutline
 )
+
+// ActiveModeNames - how modes are named to the user, e.g. in the status line and key bindings
+var ActiveModeNames = map[ActiveMode]string{
+	ActiveModeDefault:            "note",
+	ActiveModeAutocomplete:       "complete",
+	ActiveModeSearchResultSelect: "results",
+	ActiveModeLinkSelect:         "links",
+	ActiveModeFind:               "find",
+	ActiveModeOutline:            "toc",
+}
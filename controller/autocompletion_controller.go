@@ -1,8 +1,6 @@
 package controller
 
 import (
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/mattn/go-runewidth"
@@ -12,8 +10,15 @@ import (
 )
 
 func (mc *MainController) handleAutocompleteNote(str string) {
-	completeSuggestions := mc.suggestAutocompletions(str)
+	mc.showCompletions(mc.suggestAutocompletions(str))
+}
+
+func (mc *MainController) handleAutocompleteCommand(str string) {
+	mc.showCompletions(mc.suggestCommandAutocompletions(str))
+}
 
+// a single completion is filled in, otherwise the choices are listed
+func (mc *MainController) showCompletions(completeSuggestions []model.AutocompleteResult) {
 	if len(completeSuggestions) == 1 {
 		newQuery := completeSuggestions[0].CompletionStr()
 		mc.InputView.SetTextContentString(newQuery)
@@ -24,58 +29,85 @@ func (mc *MainController) handleAutocompleteNote(str string) {
 	}
 }
 
-func (mc *MainController) suggestAutocompletions(fragment string) []model.AutocompleteResult {
-	res := make([]model.AutocompleteResult, 0)
-	allFiles := mc.FileManager.FindSupportedFilePaths()
-	topCompleteDir := filepath.Dir(fragment)
-
-	var resContains func(string) bool
-	resContains = func(str string) bool {
-		for _, s := range res {
-			if s.Str == str {
-				return true
-			}
+func (mc *MainController) suggestAutocompletions(query string) []model.AutocompleteResult {
+	if typed := strings.TrimLeft(query, " "); strings.HasPrefix(typed, model.BookmarkPrefix) {
+		prefix := query[:len(query)-len(typed)] + model.BookmarkPrefix
+		res := mc.Bookmarks.Complete(strings.TrimPrefix(typed, model.BookmarkPrefix))
+		for i := range res {
+			res[i] = res[i].WithPrefix(prefix)
 		}
-		return false
+		return res
 	}
 
-	for _, f := range allFiles {
-		qp := f.QueryPath()
-		if strings.HasPrefix(qp, fragment) {
-			relativeToQ, _ := filepath.Rel(topCompleteDir, qp)
-			remainingInPath := strings.Split(relativeToQ, string(os.PathSeparator))
-			nextInPath := remainingInPath[0]
-			fullCompletion := filepath.Join(topCompleteDir, nextInPath)
-
-			if !resContains(fullCompletion) {
-				compl := model.AutocompleteResult{
-					Str:   fullCompletion,
-					IsDir: len(remainingInPath) > 1,
+	scope, prefix, fragment := model.ParseTraversalScope(query)
+
+	headings := mc.FileManager.SuggestHeadings(query)
+	if len(headings) > 0 || scope == model.TraversalScopeRoot || scope == model.TraversalScopeCurrent {
+		return headings
+	}
+
+	res := mc.suggestPathAutocompletions(fragment)
+	for i := range res {
+		res[i] = res[i].WithPrefix(prefix)
+	}
+	return res
+}
+
+// suggestCommandAutocompletions - complete the command name, or else the last argument using the command's completer
+func (mc *MainController) suggestCommandAutocompletions(str string) []model.AutocompleteResult {
+	res := make([]model.AutocompleteResult, 0)
+	typed := strings.TrimLeft(str, " ")
+	leading := str[:len(str)-len(typed)]
+
+	nameEnd := strings.Index(typed, " ")
+	if nameEnd < 0 {
+		for _, cmd := range commands {
+			for _, alias := range cmd.aliases {
+				if strings.HasPrefix(alias, typed) {
+					compl := model.AutocompleteResult{
+						Str:     alias,
+						Kind:    model.AutocompleteKindCommand,
+						Matches: model.PrefixMatches(typed),
+					}
+					res = append(res, compl.WithPrefix(leading))
 				}
-				res = append(res, compl)
 			}
 		}
+		return res
 	}
 
-	if len(res) == 0 {
-		return mc.suggestAutocompletionsLenient(fragment, allFiles)
-		// didn't match anything so try a more flexible search
+	cmd := findCommand(typed[:nameEnd])
+	if cmd == nil || cmd.completer == nil {
+		return res
+	}
+	argStart := strings.LastIndex(str, " ") + 1
+	for _, compl := range cmd.completer(mc, str[argStart:]) {
+		res = append(res, compl.WithPrefix(str[:argStart]))
 	}
-
 	return res
 }
 
-func (mc *MainController) suggestAutocompletionsLenient(fragment string, allFiles []model.FilePath) []model.AutocompleteResult {
-	res := make([]model.AutocompleteResult, 0)
+func (mc *MainController) suggestPathAutocompletions(query string) []model.AutocompleteResult {
+	allFiles, fragment := mc.FileManager.FindSupportedFilePathsForQuery(query)
+	res := mc.suggestPathAutocompletionsIn(fragment, allFiles)
 
-	for _, f := range allFiles {
-		qp := f.QueryPath()
-		fileName := filepath.Base(qp)
-		if strings.HasPrefix(fileName, fragment) {
-			compl := model.AutocompleteResult{
-				Str:   qp,
-				IsDir: false,
-			}
+	// keep the root alias if one was given
+	if alias, _ := model.SplitRootAlias(query, mc.FileManager.Roots()); alias != "" {
+		for i := range res {
+			res[i] = res[i].WithPrefix(alias + model.RootAliasSeparator)
+		}
+	}
+	return res
+}
+
+func (mc *MainController) suggestPathAutocompletionsIn(fragment string, allFiles []model.FilePath) []model.AutocompleteResult {
+	res := model.PrefixCompletePaths(fragment, allFiles)
+	if fragment == "" {
+		return res
+	}
+	// after the prefix matches, offer notes matching more loosely, best first
+	for _, compl := range model.FuzzyCompletePaths(fragment, allFiles) {
+		if !strings.HasPrefix(compl.Str, fragment) {
 			res = append(res, compl)
 		}
 	}
@@ -86,34 +118,29 @@ func (mc *MainController) suggestAutocompletionsLenient(fragment string, allFile
 func (mc *MainController) updateInput() {
 	matched, res := mc.CompletionView.Current()
 	if matched {
-		str := res.Str
-		if res.IsDir {
-			str += "/"
-		}
+		str := res.CompletionStr()
 		mc.InputView.SetTextContentString(str)
 		mc.InputView.SetCursorX(runewidth.StringWidth(str))
 	}
 }
 
-func (mc *MainController) handleCompltionModeEvent(e *egg.KeyEvent) {
+func (mc *MainController) handleCompltionModeEvent(e *egg.KeyEvent, action model.Action) {
 	// ensure conditions are correct
 	if mc.activeMode == constants.ActiveModeAutocomplete && mc.CompletionView.IsOpen() {
 		e.SetPropagate(false)
 
-		switch e.Key {
-		case egg.KeyTab, egg.KeyDown:
+		switch action {
+		case model.ActionNext:
 			mc.CompletionView.Next()
 			mc.updateInput()
-		case egg.KeyUp, egg.KeyBacktab:
+		case model.ActionPrev:
 			mc.CompletionView.Prev()
 			mc.updateInput()
-		case egg.KeyEnter:
+		case model.ActionAccept:
 			mc.setMode(constants.ActiveModeDefault)
 			// nothing else
 		default:
-			mc.setMode(constants.ActiveModeDefault)
-			e.SetPropagate(true)
-			mc.handleEventInputMode(e)
+			mc.exitToInput(e)
 		}
 	}
 }
@@ -2,9 +2,12 @@ package controller
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/mattn/go-runewidth"
 	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/constants"
 	"github.com/thomgray/notebee/model"
 	"github.com/thomgray/notebee/util"
 )
@@ -12,8 +15,10 @@ import (
 type command struct {
 	aliases     []string
 	desctiption string
-	action      func(*MainController, []string) bool
+	action      func(*MainController, []string) error
 	keyhandler  func(ke *egg.KeyEvent)
+	// completer - suggest completions for the argument being typed
+	completer func(*MainController, string) []model.AutocompleteResult
 }
 
 var commands = []*command{
@@ -24,30 +29,32 @@ var commands = []*command{
 	{
 		aliases:     []string{"q", "quit"},
 		desctiption: "Exit application",
-		action: func(mc *MainController, args []string) bool {
+		action: func(mc *MainController, args []string) error {
 			app.Stop()
-			return true
+			return nil
 		},
 	},
 	{
 		aliases:     []string{"ls", "list"},
-		desctiption: "List configured search paths",
-		action: func(mc *MainController, args []string) bool {
-			sp := mc.Config.SearchPaths
+		desctiption: "List configured search paths and their aliases",
+		action: func(mc *MainController, args []string) error {
+			roots := mc.FileManager.Roots()
 
 			mc.View.OutputView.CustomDraw(func(c egg.Canvas) {
-				for i, spath := range sp {
-					c.DrawString2(spath, 0, i)
+				for i, root := range roots {
+					alias := root.Alias + model.RootAliasSeparator
+					c.DrawString(alias, 0, i, egg.ColorCyan, c.Background, c.Attribute)
+					c.DrawString2(root.Path, runewidth.StringWidth(alias)+1, i)
 				}
 			})
 
-			return true
+			return nil
 		},
 	},
 	{
 		aliases:     []string{"l"},
 		desctiption: "List top level documents",
-		action: func(mc *MainController, args []string) bool {
+		action: func(mc *MainController, args []string) error {
 			allFilesPaths := mc.FileManager.FindSupportedFilePaths()
 
 			mc.View.OutputView.CustomDraw(func(c egg.Canvas) {
@@ -62,72 +69,235 @@ var commands = []*command{
 				mc.View.OutputView.SetBounds(curBounds)
 				mc.View.ScrollView.ReDraw()
 			}
-			return true
+			return nil
 		},
 	},
 	{
 		aliases:     []string{"sp-add", "+"},
 		desctiption: "Add a search path",
-		action: func(mc *MainController, args []string) bool {
+		completer:   completeDirectoryArg,
+		action: func(mc *MainController, args []string) error {
 			if len(args) == 0 {
-				return false
+				return missingArgument("a directory")
 			}
-			sp := args[0]
-			mc.Config.AddSearchPath(sp)
+			sp, err := util.ResolveDirectory(args[0])
+			if err != nil {
+				return err
+			}
+			err = mc.Config.AddSearchPath(sp)
 			// mc.Config.ReloadNotes()
 			mc.reloadFiles()
-			return true
+			return err
 		},
 	},
 	{
 		aliases:     []string{"sp-remove", "-"},
 		desctiption: "Remove a search path",
-		action: func(mc *MainController, args []string) bool {
+		completer:   completeSearchPathArg,
+		action: func(mc *MainController, args []string) error {
 			if len(args) == 0 {
-				return false
+				return missingArgument("a search path")
 			}
 			sp := args[0]
-			mc.Config.RemoveSearchPath(sp)
+			if !util.StringSliceContains(mc.Config.SearchPaths, sp) {
+				return util.NotFound("%s is not a search path", sp)
+			}
+			err := mc.Config.RemoveSearchPath(sp)
 			// mc.Config.ReloadNotes()
 			mc.reloadFiles()
-			return true
+			return err
 		},
 	},
 	{
 		aliases:     []string{"reload"},
 		desctiption: "Reload notes",
-		action: func(mc *MainController, args []string) bool {
-			mc.Config.Init() // to reload config
-			mc.reloadFiles() // to reload files
-			return true
+		action: func(mc *MainController, args []string) error {
+			err := mc.Config.Init() // to reload config
+			mc.reloadFiles()        // to reload files
+			if err != nil {
+				return err
+			}
+			if err := mc.loadKeymap(); err != nil {
+				return err
+			}
+			mc.showMessage("reloaded %d notes", len(mc.FileManager.IndexEntries()))
+			return nil
 		},
 	},
 	{
 		aliases:     []string{"cd"},
 		desctiption: "Change document root",
-		action: func(mc *MainController, args []string) bool {
+		completer:   completeDirectoryArg,
+		action: func(mc *MainController, args []string) error {
 			positional, flags, _ := parseOptions(args)
 			if len(positional) == 0 {
-				return false
+				return missingArgument("a directory")
+			}
+
+			path, err := util.ResolveDirectory(positional[0])
+			if err != nil {
+				return err
+			}
+			mc.Config.SetCurrentDocRoot(path)
+
+			if util.StringSliceContains(flags, "default") {
+				// make this the default root
+				err = mc.Config.SetDefaultDocRoot(path)
+			}
+			mc.reloadFiles()
+			return err
+		},
+	},
+	{
+		aliases:     []string{"todo"},
+		desctiption: "List open task items in all notes",
+		action: func(mc *MainController, args []string) error {
+			tasks := mc.FileManager.OpenTasks()
+
+			// a line for each note/section with tasks, followed by its tasks
+			height := 0
+			lastPlace := ""
+			for _, t := range tasks {
+				if place := taskPlace(t); place != lastPlace {
+					height++
+					lastPlace = place
+				}
+				height++
 			}
 
-			path := positional[0]
-			if info, exists := util.PathExists(path); exists && info.IsDir() {
-				mc.Config.SetCurrentDocRoot(path)
+			mc.View.OutputView.CustomDraw(func(c egg.Canvas) {
+				y := 0
+				lastPlace := ""
+				for _, t := range tasks {
+					if place := taskPlace(t); place != lastPlace {
+						c.DrawString(place, 0, y, egg.ColorCyan, c.Background, c.Attribute)
+						lastPlace = place
+						y++
+					}
+					c.DrawString("☐", 2, y, egg.ColorYellow, c.Background, c.Attribute)
+					c.DrawString2(t.Text, 4, y)
+					y++
+				}
+			})
 
-				if util.StringSliceContains(flags, "default") {
-					// make this the default root
-					mc.Config.SetDefaultDocRoot(path)
+			curBounds := mc.View.OutputView.GetBounds()
+			if curBounds.Height < height {
+				curBounds.Height = height
+				mc.View.OutputView.SetBounds(curBounds)
+				mc.View.ScrollView.ReDraw()
+			}
+			return nil
+		},
+	},
+	{
+		aliases:     []string{"ln", "line-numbers"},
+		desctiption: "Toggle line numbers in code blocks",
+		action: func(mc *MainController, args []string) error {
+			on := !mc.Config.LineNumbers()
+			err := mc.Config.SetLineNumbers(on)
+			mc.View.OutputView.SetLineNumbers(on)
+			mc.View.OutputView.UnbindDraw()
+			return err
+		},
+	},
+	{
+		aliases:     []string{"links"},
+		desctiption: "Toggle numbered links, listed at the end of the note",
+		action: func(mc *MainController, args []string) error {
+			on := !mc.Config.NumberLinks()
+			err := mc.Config.SetNumberLinks(on)
+			mc.View.OutputView.SetNumberLinks(on)
+			mc.View.OutputView.UnbindDraw()
+			return err
+		},
+	},
+	{
+		aliases:     []string{"history"},
+		desctiption: "List recently visited notes, or reopen the nth with `history n`",
+		action: func(mc *MainController, args []string) error {
+			recent := mc.History.Recent(historyListLength)
+			if len(args) == 0 {
+				mc.drawHistory(recent)
+				return nil
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > len(recent) {
+				return util.NotFound("no note %s in the history", args[0])
+			}
+			return mc.openPlace(recent[n-1].Place)
+		},
+	},
+	{
+		aliases:     []string{"mark"},
+		desctiption: "Bookmark the current note, section and scroll position, to jump to with `> @name`",
+		completer:   completeBookmarkArg,
+		action: func(mc *MainController, args []string) error {
+			name, err := bookmarkNameArg(args)
+			if err != nil {
+				return err
+			}
+			place, ok := mc.currentPlace()
+			if !ok {
+				return util.Invalid("no note is open to bookmark")
+			}
+			return mc.Bookmarks.Set(model.Bookmark{Name: name, Place: place})
+		},
+	},
+	{
+		aliases:     []string{"unmark"},
+		desctiption: "Remove a bookmark",
+		completer:   completeBookmarkArg,
+		action: func(mc *MainController, args []string) error {
+			name, err := bookmarkNameArg(args)
+			if err != nil {
+				return err
+			}
+			removed, err := mc.Bookmarks.Remove(name)
+			if !removed {
+				return util.NotFound("no bookmark %s%s", model.BookmarkPrefix, name)
+			}
+			return err
+		},
+	},
+	{
+		aliases:     []string{"marks"},
+		desctiption: "List bookmarks",
+		action: func(mc *MainController, args []string) error {
+			mc.drawBookmarks()
+			return nil
+		},
+	},
+	{
+		aliases:     []string{"toc"},
+		desctiption: "Toggle the table of contents beside notes",
+		action: func(mc *MainController, args []string) error {
+			on := !mc.Config.ShowTOC()
+			err := mc.Config.SetShowTOC(on)
+			mc.View.SetTOCVisible(on)
+			return err
+		},
+	},
+	{
+		aliases:     []string{"keys"},
+		desctiption: "List key bindings, in every mode or in the one named, e.g. `keys find`",
+		completer:   completeModeArg,
+		action: func(mc *MainController, args []string) error {
+			modes := model.KeymapModes
+			if len(args) > 0 {
+				mode, ok := model.ModeNamed(args[0])
+				if !ok {
+					return util.NotFound("no mode '%s'", args[0])
 				}
-				return true
+				modes = []constants.ActiveMode{mode}
 			}
-			return false
+			mc.drawKeys(modes)
+			return nil
 		},
 	},
 	{
 		aliases:     []string{"pwd"},
 		desctiption: "Output current document root",
-		action: func(mc *MainController, args []string) bool {
+		action: func(mc *MainController, args []string) error {
 			root := mc.Config.DocumentRoot()
 			str := "?"
 			if root != nil {
@@ -138,11 +308,82 @@ var commands = []*command{
 				c.DrawString2(str, 0, 0)
 			})
 
-			return true
+			return nil
 		},
 	},
 }
 
+// the note and heading path a task is under, e.g. `git/release › Deploy`
+func taskPlace(t model.TaskItem) string {
+	return strings.Join(append([]string{t.Path.QueryPath()}, t.Document.HeadingTitles()...), " › ")
+}
+
+func missingArgument(what string) error {
+	return util.Invalid("expected %s", what)
+}
+
+// the bookmark named by the only argument, with or without its prefix
+func bookmarkNameArg(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", missingArgument("a bookmark name")
+	}
+	if len(args) > 1 {
+		return "", util.Invalid("a bookmark name can't contain spaces")
+	}
+	return strings.TrimPrefix(args[0], model.BookmarkPrefix), nil
+}
+
+func completeModeArg(mc *MainController, arg string) []model.AutocompleteResult {
+	res := make([]model.AutocompleteResult, 0)
+	for _, mode := range model.KeymapModes {
+		if name := constants.ActiveModeNames[mode]; strings.HasPrefix(name, arg) {
+			res = append(res, model.AutocompleteResult{
+				Str:  name,
+				Kind: model.AutocompleteKindArgument,
+			})
+		}
+	}
+	return res
+}
+
+func completeDirectoryArg(mc *MainController, arg string) []model.AutocompleteResult {
+	if strings.HasPrefix(arg, "-") {
+		return []model.AutocompleteResult{}
+	}
+	return model.CompleteDirectories(arg)
+}
+
+func completeSearchPathArg(mc *MainController, arg string) []model.AutocompleteResult {
+	res := make([]model.AutocompleteResult, 0)
+	for _, sp := range mc.Config.SearchPaths {
+		if strings.HasPrefix(sp, arg) {
+			res = append(res, model.AutocompleteResult{
+				Str:  sp,
+				Kind: model.AutocompleteKindArgument,
+			})
+		}
+	}
+	return res
+}
+
+func completeBookmarkArg(mc *MainController, arg string) []model.AutocompleteResult {
+	res := mc.Bookmarks.Complete(strings.TrimPrefix(arg, model.BookmarkPrefix))
+	for i := range res {
+		res[i].Kind = model.AutocompleteKindArgument
+	}
+	return res
+}
+
+// findCommand - the command with this alias
+func findCommand(alias string) *command {
+	for _, cmd := range commands {
+		if util.StringSliceContains(cmd.aliases, alias) {
+			return cmd
+		}
+	}
+	return nil
+}
+
 func parseOptions(args []string) ([]string, []string, map[string]string) {
 	positional := make([]string, 0)
 	flags := make([]string, 0)
@@ -166,10 +407,10 @@ func parseOptions(args []string) ([]string, []string, map[string]string) {
 
 func (mc *MainController) handleCommand(str string) {
 	trimmed := strings.Trim(str, " ")
-	split := strings.Split(trimmed, " ")
-	if len(split) == 0 {
+	if trimmed == "" {
 		return
 	}
+	split := strings.Split(trimmed, " ")
 	cmdIn := split[0]
 	args := make([]string, 0)
 	split = split[1:]
@@ -178,25 +419,19 @@ func (mc *MainController) handleCommand(str string) {
 			args = append(args, s)
 		}
 	}
-	hit := false
-
-here:
-	for _, cmd := range commands {
-		for _, alias := range cmd.aliases {
-			if alias == cmdIn {
-				hit = cmd.action(mc, args)
-				if hit {
-					break here
-				}
-			}
-		}
+	cmd := findCommand(cmdIn)
+	if cmd == nil {
+		mc.ShowError(util.NotFound("no command '%s'", cmdIn))
+		return
 	}
-
-	if hit {
-		mc.InputView.SetTextContentString("")
-		mc.InputView.SetCursorX(0)
-		app.ReDraw()
+	if err := cmd.action(mc, args); err != nil {
+		mc.ShowError(err)
+		return
 	}
+
+	mc.InputView.SetTextContentString("")
+	mc.InputView.SetCursorX(0)
+	app.ReDraw()
 }
 
 var __helpTxt *[]model.AttributedString = nil
@@ -222,7 +457,7 @@ func initHelp() {
 
 func bootstrapCommands() {
 	// needs to be done this way for circularity reasons :(
-	commands[0].action = func(mc *MainController, args []string) bool {
+	commands[0].action = func(mc *MainController, args []string) error {
 		mc.View.OutputView.CustomDraw(func(c egg.Canvas) {
 			y := 0
 			for _, cmd := range commands {
@@ -233,6 +468,6 @@ func bootstrapCommands() {
 				y++
 			}
 		})
-		return true
+		return nil
 	}
 }
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/constants"
+	"github.com/thomgray/notebee/model"
+	"github.com/thomgray/notebee/util"
+)
+
+// handleFind - highlight the text throughout the note being displayed, and go to the first match on screen or below
+func (mc *MainController) handleFind(str string) {
+	defer app.ReDraw()
+	if mc.activeFile == nil {
+		mc.ShowError(util.Invalid("no note is open"))
+		return
+	}
+	h, err := model.FindHighlighter(str)
+	if err != nil {
+		mc.ShowError(err)
+		mc.View.OutputView.ClearFind()
+		return
+	}
+	mc.View.OutputView.Find(h, mc.View.ScrollOffset())
+	mc.setMode(constants.ActiveModeFind)
+}
+
+func (mc *MainController) handleFindModeEvent(e *egg.KeyEvent, action model.Action) {
+	e.SetPropagate(false)
+	switch action {
+	case model.ActionNext:
+		mc.View.OutputView.CycleMatch(1)
+	case model.ActionPrev:
+		mc.View.OutputView.CycleMatch(-1)
+	case model.ActionFind:
+		// find something else
+		mc.setMode(constants.ActiveModeDefault)
+		mc.setInputMode(constants.InputModeFind)
+		mc.InputView.SetTextContentString("")
+		mc.InputView.SetCursorX(0)
+	default:
+		if !mc.scroll(action) {
+			mc.exitToInput(e)
+		}
+	}
+}
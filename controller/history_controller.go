@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/model"
+	"github.com/thomgray/notebee/util"
+)
+
+// the number of notes listed by `: history`
+const historyListLength = 20
+
+// recordVisit - add the document to the history, remembering how far the one being left was scrolled
+func (mc *MainController) recordVisit(f *model.File, doc *model.Document) {
+	mc.History.UpdateScroll(mc.View.ScrollOffset())
+	err := mc.History.Visit(model.HistoryEntry{
+		Place:   model.Place{Path: f.Path, HeadingPath: doc.HeadingTitles()},
+		Visited: time.Now(),
+	})
+	if err != nil {
+		mc.ShowError(err)
+	}
+}
+
+// currentPlace - the document being displayed and how far it is scrolled
+func (mc *MainController) currentPlace() (model.Place, bool) {
+	if mc.activeFile == nil {
+		return model.Place{}, false
+	}
+	return model.Place{
+		Path:        mc.activeFile.Path,
+		HeadingPath: mc.activeDocument.HeadingTitles(),
+		Scroll:      mc.View.ScrollOffset(),
+	}, true
+}
+
+func (mc *MainController) goBack() {
+	mc.History.UpdateScroll(mc.View.ScrollOffset())
+	e, ok, err := mc.History.Back()
+	if !ok {
+		mc.ShowError(util.NotFound("nothing to go back to"))
+		return
+	}
+	mc.showHistoryEntry(e)
+	if err != nil {
+		mc.ShowError(err)
+	}
+}
+
+func (mc *MainController) goForward() {
+	mc.History.UpdateScroll(mc.View.ScrollOffset())
+	e, ok, err := mc.History.Forward()
+	if !ok {
+		mc.ShowError(util.NotFound("nothing to go forward to"))
+		return
+	}
+	mc.showHistoryEntry(e)
+	if err != nil {
+		mc.ShowError(err)
+	}
+}
+
+// showHistoryEntry - display the place in the history without adding to it, scrolled as it was left
+func (mc *MainController) showHistoryEntry(e model.HistoryEntry) {
+	loc, err := mc.placeLocation(e.Place)
+	if err != nil {
+		mc.ShowError(err)
+		return
+	}
+	mc.FileManager.SetLocation(loc)
+	mc.showDocument(loc.File, loc.Document)
+	mc.View.OutputView.ScrollToY(e.Scroll)
+}
+
+func (mc *MainController) placeLocation(p model.Place) (*model.Location, error) {
+	loc, err := mc.FileManager.LocationOfFile(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	loc.Document = p.Section(loc.File.Document)
+	return loc, nil
+}
+
+// openPlace - visit a place again, e.g. from the history or a bookmark, adding it to the history
+func (mc *MainController) openPlace(p model.Place) error {
+	loc, err := mc.placeLocation(p)
+	if err != nil {
+		return err
+	}
+	mc.FileManager.SetLocation(loc)
+	mc.SetActiveDocument(loc.File, loc.Document)
+	mc.View.OutputView.ScrollToY(p.Scroll)
+	return nil
+}
+
+// the note and heading path of a place, e.g. `git/release › Deploy`
+func (mc *MainController) placeName(p model.Place) string {
+	return strings.Join(append([]string{mc.FileManager.QueryPathOf(p.Path)}, p.HeadingPath...), " › ")
+}
+
+func (mc *MainController) drawHistory(recent []model.HistoryEntry) {
+	mc.View.OutputView.CustomDraw(func(c egg.Canvas) {
+		for i, e := range recent {
+			number := fmt.Sprintf("%2d", i+1)
+			c.DrawString(number, 0, i, egg.ColorMagenta, c.Background, c.Attribute)
+			place := mc.placeName(e.Place)
+			c.DrawString2(place, 4, i)
+			c.DrawString(e.Visited.Format("Jan 2 15:04"), 6+runewidth.StringWidth(place), i, egg.ColorBrightBlack, c.Background, c.Attribute)
+		}
+	})
+
+	curBounds := mc.View.OutputView.GetBounds()
+	if curBounds.Height < len(recent) {
+		curBounds.Height = len(recent)
+		mc.View.OutputView.SetBounds(curBounds)
+		mc.View.ScrollView.ReDraw()
+	}
+}
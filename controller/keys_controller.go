@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/config"
+	"github.com/thomgray/notebee/constants"
+)
+
+// the column actions are listed in by `: keys`
+const keysActionColumn = 14
+
+// drawKeys - list the bindings of each mode under the mode's name
+func (mc *MainController) drawKeys(modes []constants.ActiveMode) {
+	km := mc.Keymap
+	height := 1
+	for _, mode := range modes {
+		height += 1 + len(km.Bindings(mode))
+	}
+
+	mc.View.OutputView.CustomDraw(func(c egg.Canvas) {
+		c.DrawString(fmt.Sprintf("%s keys, configured in %s", km.Preset, config.KeysPath()), 0, 0, egg.ColorBrightBlack, c.Background, c.Attribute)
+		y := 1
+		for _, mode := range modes {
+			c.DrawString(constants.ActiveModeNames[mode], 0, y, egg.ColorCyan, c.Background, c.Attribute)
+			y++
+			for _, b := range km.Bindings(mode) {
+				c.DrawString(b.Chord.String(), 2, y, egg.ColorMagenta, c.Background, c.Attribute)
+				c.DrawString2(string(b.Action), keysActionColumn, y)
+				y++
+			}
+		}
+	})
+
+	curBounds := mc.View.OutputView.GetBounds()
+	if curBounds.Height < height {
+		curBounds.Height = height
+		mc.View.OutputView.SetBounds(curBounds)
+		mc.View.ScrollView.ReDraw()
+	}
+}
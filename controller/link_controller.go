@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"os/exec"
+
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/constants"
+	"github.com/thomgray/notebee/htmlrender"
+	"github.com/thomgray/notebee/model"
+	"github.com/thomgray/notebee/util"
+)
+
+// enterLinkSelect - select the first (or last) link on screen. Returns false if there are none
+func (mc *MainController) enterLinkSelect(step int) bool {
+	links := mc.View.VisibleLinks()
+	if len(links) == 0 {
+		return false
+	}
+	mc.setMode(constants.ActiveModeLinkSelect)
+	if step < 0 {
+		mc.selectLink(links[len(links)-1])
+	} else {
+		mc.selectLink(links[0])
+	}
+	return true
+}
+
+func (mc *MainController) selectLink(l htmlrender.LinkPosition) {
+	mc.selectedLink = &l
+	mc.View.OutputView.SetSelectedLink(l.Node)
+}
+
+func (mc *MainController) clearSelectedLink() {
+	mc.selectedLink = nil
+	mc.View.OutputView.SetSelectedLink(nil)
+}
+
+// cycleLink - select the next link on screen, or the previous for a negative step, wrapping around
+func (mc *MainController) cycleLink(step int) {
+	links := mc.View.VisibleLinks()
+	if len(links) == 0 {
+		mc.setMode(constants.ActiveModeDefault)
+		return
+	}
+	i := -1
+	for j, l := range links {
+		if mc.selectedLink != nil && l.Node == mc.selectedLink.Node {
+			i = j
+			break
+		}
+	}
+	switch {
+	case i >= 0:
+		i = (i + step + len(links)) % len(links)
+	case step < 0:
+		i = len(links) - 1
+	default:
+		i = 0
+	}
+	mc.selectLink(links[i])
+}
+
+func (mc *MainController) handleLinkSelectModeEvent(e *egg.KeyEvent, action model.Action) {
+	e.SetPropagate(false)
+	switch action {
+	case model.ActionNext:
+		mc.cycleLink(1)
+	case model.ActionPrev:
+		mc.cycleLink(-1)
+	case model.ActionOpen:
+		selected := mc.selectedLink
+		mc.setMode(constants.ActiveModeDefault)
+		if selected != nil {
+			mc.followLink(selected.Href)
+		}
+	default:
+		if mc.scroll(action) {
+			// keep the selection on screen
+			mc.cycleLink(0)
+		} else {
+			mc.exitToInput(e)
+		}
+	}
+}
+
+// followLink - open the note a relative link leads to, scrolled to the heading in its fragment.
+// External links are handed to the opener command
+func (mc *MainController) followLink(href string) {
+	if model.IsExternalLink(href) {
+		mc.openExternalLink(href)
+		return
+	}
+	target, err := mc.FileManager.ResolveLink(mc.activeFile, href)
+	if err != nil {
+		mc.ShowError(err)
+		return
+	}
+
+	loc := target.Location
+	mc.FileManager.SetLocation(loc)
+	mc.SetActiveDocument(loc.File, loc.Document)
+	if target.Section != nil {
+		mc.View.OutputView.ScrollToNode(target.Section.Node)
+		sectionLoc := *loc
+		sectionLoc.Document = target.Section
+		mc.FileManager.SetLocation(&sectionLoc)
+	}
+	app.ReDraw()
+}
+
+func (mc *MainController) openExternalLink(href string) {
+	args := mc.Config.Opener()
+	cmd := exec.Command(args[0], append(args[1:], href)...)
+	if err := cmd.Start(); err != nil {
+		mc.ShowError(&util.Error{Kind: util.ErrorInvalid, Reason: "could not open " + href, Cause: err})
+		return
+	}
+	go cmd.Wait()
+}
@@ -1,18 +1,24 @@
 package controller
 
 import (
+	"fmt"
+	"log"
 	"strings"
+	"sync"
 
 	"github.com/thomgray/notebee/constants"
+	"github.com/thomgray/notebee/htmlrender"
 	"github.com/thomgray/notebee/model"
 
 	"github.com/thomgray/egg"
 	"github.com/thomgray/notebee/config"
+	"github.com/thomgray/notebee/util"
 	"github.com/thomgray/notebee/view"
 )
 
 type inputCommand struct {
 	key        egg.Key
+	action     model.Action
 	activeMode constants.ActiveMode
 }
 
@@ -23,12 +29,19 @@ type MainController struct {
 	ModalMenu         *view.ModalMenu
 	CompletionView    *view.CompletionView
 	SearchResultsView *view.SearchResultsView
+	StatusView        *view.StatusView
 	Config            *config.Config
 	FileManager       *model.FileManager
+	History           *model.History
+	Bookmarks         *model.Bookmarks
+	Keymap            *model.Keymap
 	activeDocument    *model.Document
 	activeFile        *model.File
 	lastCommand       inputCommand
 	activeMode        constants.ActiveMode
+	selectedLink      *htmlrender.LinkPosition
+	watcher           *model.Watcher
+	mux               sync.Mutex
 }
 
 // Mode ...
@@ -61,10 +74,13 @@ func InitMainController(config *config.Config) *MainController {
 	app.OnResizeEvent(func(re *egg.ResizeEvent) {
 		mc.View.Refit(re.Width, re.Height)
 		mc.SearchResultsView.Refit(re.Width, re.Height)
+		mc.StatusView.Refit(re.Width, re.Height)
 		mc.CompletionView.Resize()
 		app.ReDraw()
 	})
 
+	mc.StatusView = view.MakeStatusView(mc.View)
+	app.AddView(mc.StatusView.View)
 	app.AddView(mc.SearchResultsView.View)
 
 	mc.View.OutputView.SetZIndex(0)
@@ -79,17 +95,83 @@ func InitMainController(config *config.Config) *MainController {
 
 func (mc *MainController) init() {
 	mc.reloadFiles()
+	var err error
+	if mc.History, err = model.LoadHistory(config.HistoryPath()); err != nil {
+		mc.ShowError(err)
+	}
+	if mc.Bookmarks, err = model.LoadBookmarks(config.BookmarksPath()); err != nil {
+		mc.ShowError(err)
+	}
+	mc.loadKeymap()
 	bootstrapCommands()
+	mc.View.OutputView.SetLineNumbers(mc.Config.LineNumbers())
+	mc.View.OutputView.SetNumberLinks(mc.Config.NumberLinks())
+	mc.View.SetTOCVisible(mc.Config.ShowTOC())
+	mc.watcher = mc.FileManager.Watch(&mc.mux, mc.handleIndexChanges)
+}
+
+// called from the watcher goroutine when notes change on disk
+func (mc *MainController) handleIndexChanges(changes model.IndexChanges) {
+	mc.mux.Lock()
+	defer mc.mux.Unlock()
+	defer app.ReDraw()
+
+	if mc.activeFile != nil && changes.Touches(mc.activeFile.Path) {
+		if loc := mc.FileManager.RefreshLocation(); loc != nil {
+			mc.activeFile = loc.File
+			mc.activeDocument = loc.Document
+			mc.View.UpdateActiveDocument(loc.File, loc.Document)
+		}
+	}
+
+	if mc.CompletionView.IsOpen() && mc.activeMode == constants.ActiveModeDefault {
+		mc.CompletionView.SetCompletions(mc.suggestAutocompletions(mc.InputView.GetTextContentString()))
+	}
 }
 
-func (mc *MainController) reloadFiles() {
-	// mc.FileManager.LoadFiles(mc.Config.NotePaths)
+func (mc *MainController) reloadFiles() int {
+	if root := mc.Config.DocumentRoot(); root != nil {
+		ac, _ := config.GetAppConfig()
+		mc.StatusView.SetRoot(util.AbbreviateHome(*root, ac.HomeDir))
+	} else {
+		mc.StatusView.SetRoot("")
+	}
+	return mc.FileManager.Reload().Loaded()
+}
+
+// loadKeymap - load the key bindings, keeping those that could be loaded if there is an error
+func (mc *MainController) loadKeymap() error {
+	km, err := model.LoadKeymap(config.KeysPath())
+	mc.Keymap = km
+	if err != nil {
+		mc.ShowError(err)
+	}
+	return err
+}
+
+// ShowError - show why something failed in the status line, until the next key press
+func (mc *MainController) ShowError(err error) {
+	log.Println(err)
+	mc.StatusView.SetError(err.Error())
+}
+
+// showMessage - show a message in the status line until the next key press
+func (mc *MainController) showMessage(format string, a ...interface{}) {
+	mc.StatusView.SetMessage(fmt.Sprintf(format, a...))
 }
 
 func (mc *MainController) setMode(mode constants.ActiveMode) {
 	mc.activeMode = mode
+	mc.StatusView.SetMode(mode)
+	if mode != constants.ActiveModeLinkSelect {
+		mc.clearSelectedLink()
+	}
+	if mode != constants.ActiveModeFind {
+		mc.View.OutputView.ClearFind()
+	}
+	mc.View.TOCView.Focus(mode == constants.ActiveModeOutline)
 	switch mode {
-	case constants.ActiveModeDefault:
+	case constants.ActiveModeDefault, constants.ActiveModeLinkSelect, constants.ActiveModeFind, constants.ActiveModeOutline:
 		mc.CompletionView.Close()
 		mc.SearchResultsView.Close()
 	case constants.ActiveModeAutocomplete:
@@ -100,69 +182,112 @@ func (mc *MainController) setMode(mode constants.ActiveMode) {
 }
 
 func (mc *MainController) keyEventDelegate(e *egg.KeyEvent) {
+	mc.mux.Lock()
+	defer mc.mux.Unlock()
 	defer app.ReDraw()
-	switch e.Key {
-	case egg.KeyEsc:
+	mc.StatusView.ClearMessage()
+	action := mc.Keymap.Action(mc.activeMode, model.ChordOf(e))
+	if action == model.ActionCancel {
 		mc.setMode(constants.ActiveModeDefault)
 		e.SetPropagate(false)
-		app.ReDraw()
 		return
 	}
 
 	defer (func() {
 		mc.lastCommand.activeMode = mc.activeMode
 		mc.lastCommand.key = e.Key
+		mc.lastCommand.action = action
 	})()
 
 	switch mc.activeMode {
 	case constants.ActiveModeDefault:
-		// if you are entering tab mode
-		if e.Key == egg.KeyTab && mc.lastCommand.key == egg.KeyTab && mc.CompletionView.IsOpen() {
-			// log.Println("In tabby mode")
+		// completing twice in a row moves through the completions
+		if action == model.ActionComplete && mc.lastCommand.action == model.ActionComplete && mc.CompletionView.IsOpen() {
 			mc.setMode(constants.ActiveModeAutocomplete)
-			mc.handleCompltionModeEvent(e)
+			mc.handleCompltionModeEvent(e, model.ActionNext)
 		} else {
-			mc.handleEventInputMode(e)
+			mc.handleEventInputMode(e, action)
 		}
 	case constants.ActiveModeAutocomplete:
-		mc.handleCompltionModeEvent(e)
+		mc.handleCompltionModeEvent(e, action)
 	case constants.ActiveModeSearchResultSelect:
-		mc.handleSearchResultModeEvent(e)
-	}
-}
-
-func (mc *MainController) handleEventInputMode(e *egg.KeyEvent) {
-	if mc.InputView.GetCursorX() == 0 {
-		switch e.Char {
-		case '?':
-			e.SetPropagate(false)
-			mc.setInputMode(constants.InputModeSearch)
-			app.ReDraw()
-			return
-		case '>':
-			e.SetPropagate(false)
-			mc.setInputMode(constants.InputModeTraverse)
-			app.ReDraw()
-			return
-		case ':':
-			e.SetPropagate(false)
-			mc.setInputMode(constants.InputModeCommand)
-			app.ReDraw()
-			return
-		}
+		mc.handleSearchResultModeEvent(e, action)
+	case constants.ActiveModeLinkSelect:
+		mc.handleLinkSelectModeEvent(e, action)
+	case constants.ActiveModeFind:
+		mc.handleFindModeEvent(e, action)
+	case constants.ActiveModeOutline:
+		mc.handleOutlineModeEvent(e, action)
 	}
+}
+
+// exitToInput - leave the active mode and handle the key as if it had been pressed at the prompt
+func (mc *MainController) exitToInput(e *egg.KeyEvent) {
+	mc.setMode(constants.ActiveModeDefault)
+	e.SetPropagate(true)
+	mc.handleEventInputMode(e, mc.Keymap.Action(constants.ActiveModeDefault, model.ChordOf(e)))
+}
 
-	switch e.Key {
-	case egg.KeyEnter:
+// scroll - scroll the output for a scroll or page action. Returns false for any other action
+func (mc *MainController) scroll(action model.Action) bool {
+	switch action {
+	case model.ActionScrollUp:
+		mc.View.ScrollLines(-1)
+	case model.ActionScrollDown:
+		mc.View.ScrollLines(1)
+	case model.ActionPageUp:
+		mc.View.ScrollLines(-mc.View.PageHeight())
+	case model.ActionPageDown:
+		mc.View.ScrollLines(mc.View.PageHeight())
+	default:
+		return false
+	}
+	return true
+}
+
+func (mc *MainController) handleEventInputMode(e *egg.KeyEvent, action model.Action) {
+	// characters only act at the start of the prompt, otherwise they are typed
+	if model.ChordOf(e).IsChar() && mc.InputView.GetCursorX() != 0 {
+		return
+	}
+
+	switch action {
+	case model.ActionTraverse:
+		mc.setInputMode(constants.InputModeTraverse)
+	case model.ActionSearch:
+		mc.setInputMode(constants.InputModeSearch)
+	case model.ActionCommand:
+		mc.setInputMode(constants.InputModeCommand)
+	case model.ActionFind:
+		mc.setInputMode(constants.InputModeFind)
+	case model.ActionTOC:
+		mc.focusOutline()
+	case model.ActionBack:
+		mc.goBack()
+	case model.ActionForward:
+		mc.goForward()
+	case model.ActionSubmit:
 		mc.handleEnter(e)
-	case egg.KeyTab:
-		e.SetPropagate(false)
-		mc.handleAutocomplete(mc.InputView.GetTextContentString())
-	case egg.KeyUp, egg.KeyDown:
-		e.SetPropagate(false)
+	case model.ActionComplete, model.ActionSelectLastLink:
+		txt := mc.InputView.GetTextContentString()
+		// with nothing typed, select the links on screen
+		step := 1
+		if action == model.ActionSelectLastLink {
+			step = -1
+		}
+		if txt == "" && mc.enterLinkSelect(step) {
+			break
+		}
+		if action == model.ActionComplete {
+			mc.handleAutocomplete(txt)
+		}
+	case model.ActionScrollUp, model.ActionScrollDown, model.ActionPageUp, model.ActionPageDown:
 		mc.CompletionView.SetVisible(false)
-		mc.View.HandleKeyEvent(e)
+		mc.scroll(action)
+	default:
+		return
 	}
+	e.SetPropagate(false)
 }
 
 func (mc *MainController) handleEventMenuMode(e *egg.KeyEvent) {
@@ -205,13 +330,27 @@ func (mc *MainController) handleEnter(e *egg.KeyEvent) {
 		mc.handleSearch(txt)
 	case constants.InputModeCommand:
 		mc.handleCommand(txt)
+	case constants.InputModeFind:
+		mc.handleFind(txt)
 	}
 }
 
 // SetActiveFile ...
 func (mc *MainController) SetActiveFile(f *model.File) {
+	mc.SetActiveDocument(f, f.Document)
+}
+
+// SetActiveDocument - set the active file and the section of it being displayed, adding it to the history
+func (mc *MainController) SetActiveDocument(f *model.File, doc *model.Document) {
+	mc.recordVisit(f, doc)
+	mc.showDocument(f, doc)
+}
+
+func (mc *MainController) showDocument(f *model.File, doc *model.Document) {
 	mc.activeFile = f
-	mc.View.SetActiveFile(f)
+	mc.activeDocument = doc
+	mc.View.SetActiveDocument(f, doc)
+	mc.StatusView.SetPath(mc.FileManager.QueryPathOf(f.Path))
 	mc.InputView.SetTextContentString("")
 	mc.InputView.SetCursorX(0)
 }
@@ -230,6 +369,8 @@ func (mc *MainController) handleAutocomplete(str string) {
 	switch inputMode {
 	case constants.InputModeTraverse:
 		mc.handleAutocompleteNote(str)
+	case constants.InputModeCommand:
+		mc.handleAutocompleteCommand(str)
 	}
 }
 
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/constants"
+	"github.com/thomgray/notebee/model"
+)
+
+// focusOutline - select headings from the table of contents, showing it if it is hidden
+func (mc *MainController) focusOutline() {
+	if mc.activeFile == nil {
+		return
+	}
+	if !mc.View.TOCView.IsVisible() {
+		mc.View.SetTOCVisible(true)
+	}
+	if mc.View.TOCView.IsEmpty() {
+		return
+	}
+	mc.setMode(constants.ActiveModeOutline)
+}
+
+func (mc *MainController) handleOutlineModeEvent(e *egg.KeyEvent, action model.Action) {
+	e.SetPropagate(false)
+	toc := mc.View.TOCView
+	switch action {
+	case model.ActionPrev:
+		toc.MoveSelection(-1)
+	case model.ActionNext:
+		toc.MoveSelection(1)
+	case model.ActionCollapse:
+		toc.SetCollapsed(true)
+	case model.ActionExpand:
+		toc.SetCollapsed(false)
+	case model.ActionOpen:
+		if section := toc.Selected(); section != nil {
+			mc.jumpToSection(section)
+		}
+	case model.ActionTOC:
+		mc.setMode(constants.ActiveModeDefault)
+	default:
+		mc.exitToInput(e)
+	}
+}
+
+// jumpToSection - scroll to a heading of the active file, displaying the whole file if the section
+// being displayed doesn't contain it
+func (mc *MainController) jumpToSection(section *model.Document) {
+	f := mc.activeFile
+	if mc.activeDocument == nil || !section.IsWithin(mc.activeDocument) {
+		mc.SetActiveDocument(f, f.Document)
+	}
+	mc.View.OutputView.ScrollToNode(section.Node)
+	loc := model.Location{File: f, Document: section}
+	if cur := mc.FileManager.CurrentLocation; cur != nil && cur.File == f {
+		loc = *cur
+		loc.Document = section
+	}
+	mc.FileManager.SetLocation(&loc)
+	app.ReDraw()
+}
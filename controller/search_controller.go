@@ -1,58 +1,67 @@
 package controller
 
 import (
-	"log"
-	"strings"
-
 	"github.com/thomgray/egg"
 	"github.com/thomgray/notebee/constants"
 	"github.com/thomgray/notebee/model"
+	"github.com/thomgray/notebee/util"
 )
 
 func (mc *MainController) handleSearch(str string) {
 	defer app.ReDraw()
-	var scoredFiles []*model.SearchResultItem
-	allPaths := mc.FileManager.FindSupportedFilePaths()
-	for _, p := range allPaths {
-		f := model.LoadCodeFile(p.Full)
-		content := string(f.Content)
-		if strings.Contains(content, str) {
-			scoredFiles = append(scoredFiles, &model.SearchResultItem{
-				File:  f,
-				Path:  p,
-				Score: 1,
-			})
-		}
+	scoredFiles, err := mc.FileManager.Search(str)
+	if err != nil {
+		mc.ShowError(err)
+		return
 	}
 
-	for _, s := range scoredFiles {
-		log.Printf("scored file name=%s path=%s", s.Path.Relative, s.Path.QueryPath())
-	}
-
-	if len(scoredFiles) > 0 {
-		mc.setMode(constants.ActiveModeSearchResultSelect)
-		mc.SearchResultsView.SetItems(scoredFiles)
-		mc.SearchResultsView.Open()
+	if len(scoredFiles) == 0 {
+		mc.ShowError(util.NotFound("no notes match '%s'", str))
+		return
 	}
+	mc.setMode(constants.ActiveModeSearchResultSelect)
+	mc.SearchResultsView.SetItems(scoredFiles)
+	mc.SearchResultsView.Open()
 }
 
-func (mc *MainController) handleSearchResultModeEvent(e *egg.KeyEvent) {
+func (mc *MainController) handleSearchResultModeEvent(e *egg.KeyEvent, action model.Action) {
 	e.SetPropagate(false)
-	switch e.Key {
-	case egg.KeyUp, egg.KeyBacktab:
+	switch action {
+	case model.ActionPrev:
 		mc.SearchResultsView.Prev()
-	case egg.KeyDown, egg.KeyTAB:
+	case model.ActionNext:
 		mc.SearchResultsView.Next()
-	case egg.KeyEnter:
+	case model.ActionOpen:
 		result := mc.SearchResultsView.Selected()
 		if result != nil {
 			mc.setMode(constants.ActiveModeDefault)
 			mc.setInputMode(constants.InputModeTraverse)
-			mc.handleTraverse(result.Path.QueryPath())
+			mc.openSearchResult(result)
 		}
 	default:
-		mc.setMode(constants.ActiveModeDefault)
-		e.SetPropagate(true)
-		mc.handleEventInputMode(e)
+		mc.exitToInput(e)
+	}
+}
+
+// open the whole file, scrolled to the section of the match with the search terms highlighted
+// and the line of the match in view
+func (mc *MainController) openSearchResult(result *model.SearchResultItem) {
+	loc, err := mc.FileManager.Traverse("* " + result.Path.QualifiedQueryPath())
+	if err != nil {
+		mc.ShowError(err)
+		return
+	}
+	mc.SetActiveDocument(loc.File, loc.Document)
+	mc.View.OutputView.SetHighlight(result.Highlight)
+	mc.View.OutputView.RevealHighlight(result.Match)
+
+	if len(result.HeadingPath) > 0 {
+		if section := loc.File.Document.Traverse(result.HeadingPath); section != nil {
+			mc.View.OutputView.ScrollToNode(section.Node)
+			sectionLoc := *loc
+			sectionLoc.Document = section
+			mc.FileManager.SetLocation(&sectionLoc)
+		}
 	}
+	app.ReDraw()
 }
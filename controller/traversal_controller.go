@@ -3,29 +3,56 @@ package controller
 import (
 	"strings"
 
+	"github.com/mattn/go-runewidth"
+	"github.com/thomgray/egg"
 	"github.com/thomgray/notebee/model"
+	"github.com/thomgray/notebee/util"
 )
 
 func (mc *MainController) handleTraverse(str string) {
-	allPaths := mc.FileManager.FindSupportedFilePaths()
-	complete := func(file *model.File) {
-		mc.SetActiveFile(file)
-		app.ReDraw()
+	if name := strings.TrimSpace(str); strings.HasPrefix(name, model.BookmarkPrefix) {
+		mc.jumpToBookmark(strings.TrimPrefix(name, model.BookmarkPrefix))
+		return
 	}
+	if strings.TrimSpace(str) == "" {
+		return
+	}
+	loc, err := mc.FileManager.Traverse(str)
+	if err != nil {
+		mc.ShowError(err)
+		return
+	}
+	mc.SetActiveDocument(loc.File, loc.Document)
+	app.ReDraw()
+}
 
-	// can do something with matching dirs perhaps?
-	// dirPaths := mc.FileManager.FindPossibleBasePaths()
-	// log.Println(">>>>>> dirs = ", dirPaths)
-
-	for _, p := range allPaths {
-		qp := p.QueryPath()
+func (mc *MainController) jumpToBookmark(name string) {
+	mark, ok := mc.Bookmarks.Get(name)
+	if !ok {
+		mc.ShowError(util.NotFound("no bookmark %s%s", model.BookmarkPrefix, name))
+		return
+	}
+	if err := mc.openPlace(mark.Place); err != nil {
+		mc.ShowError(err)
+		return
+	}
+	app.ReDraw()
+}
 
-		if strings.EqualFold(qp, str) {
-			// is exact match
-			f := model.LoadCodeFile(p.Full)
-			if f != nil {
-				complete(f)
-			}
+func (mc *MainController) drawBookmarks() {
+	marks := mc.Bookmarks.Marks
+	mc.View.OutputView.CustomDraw(func(c egg.Canvas) {
+		for i, m := range marks {
+			name := model.BookmarkPrefix + m.Name
+			c.DrawString(name, 0, i, egg.ColorYellow, c.Background, c.Attribute)
+			c.DrawString2(mc.placeName(m.Place), runewidth.StringWidth(name)+2, i)
 		}
+	})
+
+	curBounds := mc.View.OutputView.GetBounds()
+	if curBounds.Height < len(marks) {
+		curBounds.Height = len(marks)
+		mc.View.OutputView.SetBounds(curBounds)
+		mc.View.ScrollView.ReDraw()
 	}
 }
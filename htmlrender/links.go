@@ -0,0 +1,226 @@
+package htmlrender
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/util"
+	"golang.org/x/net/html"
+)
+
+// LinkPosition - where a link was drawn, and its number in the reference list if links are numbered
+type LinkPosition struct {
+	Node   *html.Node
+	Href   string
+	Number int
+	X      int
+	Y      int
+}
+
+// render the link text followed by its number in the reference list, e.g. `text[3]`.
+// A href linked to more than once keeps its first number
+func renderNumberedAnchor(n *html.Node, href string, c RenderingContext) (PostRenderingContext, int) {
+	number := linkNumber(c.result.Links, href)
+
+	linkC := linkContext(n, c)
+	linkC.Canvas.Attribute |= egg.AttrUnderline
+	prc := renderChildren(n, linkC, c)
+	prc = drawInline("["+strconv.Itoa(number)+"]", egg.ColorMagenta, c.applyPost(prc))
+	return prc, number
+}
+
+// linkContext - the context link text is drawn in: blue, or reversed out if it is the selected link
+func linkContext(n *html.Node, c RenderingContext) RenderingContext {
+	c.Canvas.Foreground = egg.ColorBlue
+	if isSelectedLink(n, c) {
+		c.Canvas.Foreground = egg.ColorBlack
+		c.Canvas.Background = egg.ColorCyan
+	}
+	return c
+}
+
+func isSelectedLink(n *html.Node, c RenderingContext) bool {
+	return n != nil && c.options != nil && c.options.SelectedLink == n
+}
+
+// the number of the href if it has already been linked to, otherwise the next number
+func linkNumber(links []LinkPosition, href string) int {
+	max := 0
+	for _, l := range links {
+		if l.Href == href && l.Number > 0 {
+			return l.Number
+		}
+		if l.Number > max {
+			max = l.Number
+		}
+	}
+	return max + 1
+}
+
+// drawInline - draw a short string that mustn't be broken at the cursor, wrapping to the next line if it doesn't fit
+func drawInline(s string, fg egg.Color, c RenderingContext) PostRenderingContext {
+	w := runewidth.StringWidth(s)
+	if c.cursorX+w > c.rightMargin && c.cursorX > c.leftMargin {
+		c.cursorX = c.leftMargin
+		c.cursorY++
+	}
+	c.Canvas.DrawString(s, c.cursorX, c.cursorY, fg, c.Canvas.Background, c.Canvas.Attribute)
+	prc := PostRenderingContext{}.noOp(c)
+	prc.cursorX += w
+	prc.endsInWhitespace = false
+	prc.didEndBlock = false
+	return prc
+}
+
+// renderLinkReferences - list the hrefs of the numbered links
+func renderLinkReferences(rc RenderingContext) PostRenderingContext {
+	prc := PostRenderingContext{}.noOp(rc)
+	numbered := make([]LinkPosition, 0)
+	for _, l := range rc.result.Links {
+		if l.Number > len(numbered) {
+			numbered = append(numbered, l)
+		}
+	}
+	if len(numbered) == 0 {
+		return prc
+	}
+
+	c := rc.applyBlock("div")
+	c = drawSectionRule("Links", c)
+	for _, l := range numbered {
+		label := "[" + strconv.Itoa(l.Number) + "] "
+		c.Canvas.DrawString(label, c.leftMargin, c.cursorY, egg.ColorMagenta, c.Canvas.Background, c.Canvas.Attribute)
+		x := c.leftMargin + runewidth.StringWidth(label)
+		toDraw := l.Href
+		for {
+			head, tail := splitAtWidth(toDraw, util.MaxInt(c.rightMargin-x, 1))
+			c.Canvas.DrawString(head, x, c.cursorY, egg.ColorBlue, c.Canvas.Background, c.Canvas.Attribute)
+			c.cursorY++
+			if tail == "" {
+				break
+			}
+			toDraw = tail
+		}
+	}
+	prc = PostRenderingContext{}.noOp(c)
+	prc.cursorY--
+	prc.didEndBlock = false
+	return prc.applyBlock(rc)
+}
+
+// draw a titled rule across the width, e.g. `── Links ─────`, returning the context on the next line
+func drawSectionRule(title string, c RenderingContext) RenderingContext {
+	head := "── " + title + " "
+	rest := util.MaxInt(c.rightMargin-c.leftMargin-runewidth.StringWidth(head), 0)
+	c.Canvas.DrawString(head+strings.Repeat("─", rest), c.leftMargin, c.cursorY, egg.ColorMagenta, c.Canvas.Background, c.Canvas.Attribute)
+	c.Canvas.DrawString(title, c.leftMargin+3, c.cursorY, egg.ColorMagenta, c.Canvas.Background, c.Canvas.Attribute|egg.AttrBold)
+	c.cursorY++
+	c.didEndBlock = true
+	return c
+}
+
+var superscriptDigits = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+func superscript(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(superscriptDigits[r-'0'])
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// blackfriday renders a footnote reference as `<sup class="footnote-ref"><a href="#fn:id">1</a></sup>`
+func isFootnoteRef(a *html.Node) bool {
+	href, _ := getAttribute(a, "href")
+	return strings.HasPrefix(href, "#fn:") && a.Parent != nil && a.Parent.Data == "sup"
+}
+
+func renderFootnoteRef(a *html.Node, c RenderingContext) PostRenderingContext {
+	href, _ := getAttribute(a, "href")
+	if c.result != nil {
+		c.result.footnoteRefs = append(c.result.footnoteRefs, strings.TrimPrefix(href, "#fn:"))
+	}
+	text, err := nodeText(a)
+	if err != nil {
+		return renderChildren(a, c, c)
+	}
+	return drawInline(superscript(text), egg.ColorCyan, c)
+}
+
+// blackfriday renders the footnotes as `<div class="footnotes"><hr><ol><li id="fn:id">...</li></ol></div>`
+func isFootnotes(n *html.Node) bool {
+	class, _ := getAttribute(n, "class")
+	return n.Type == html.ElementNode && n.Data == "div" && class == "footnotes"
+}
+
+func renderFootnotes(n *html.Node, rc RenderingContext) PostRenderingContext {
+	if rc.result != nil {
+		rc.result.footnotesDrawn = true
+	}
+	c := drawSectionRule("Footnotes", rc)
+	prc := PostRenderingContext{}.noOp(c)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "ol" {
+			prc = renderList(child, c)
+			c = c.applyPost(prc)
+		}
+	}
+	return prc
+}
+
+// when rendering a section, the footnotes it refers to are at the end of the document, so draw those here
+func renderReferencedFootnotes(nodes []*html.Node, rc RenderingContext) PostRenderingContext {
+	prc := PostRenderingContext{}.noOp(rc)
+	if len(nodes) == 0 {
+		return prc
+	}
+	root := nodes[0]
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	footnotes := findNode(root, isFootnotes)
+	if footnotes == nil {
+		return prc
+	}
+	list := findNode(footnotes, func(n *html.Node) bool { return n.Type == html.ElementNode && n.Data == "ol" })
+	if list == nil {
+		return prc
+	}
+
+	c := drawSectionRule("Footnotes", rc.applyBlock("div"))
+	c.listType = "ol"
+	c = c.setLeftMargin(c.leftMargin + 2)
+	index := 0
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.Data != "li" {
+			continue
+		}
+		id, _ := getAttribute(li, "id")
+		if util.StringSliceContains(rc.result.footnoteRefs, strings.TrimPrefix(id, "fn:")) {
+			c.listItemIndex = index
+			prc = renderRecursive(li, c)
+			c = c.applyPost(prc)
+		}
+		index++
+	}
+	prc.cursorX = rc.leftMargin
+	return prc
+}
+
+func findNode(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findNode(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package htmlrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/util"
+	"golang.org/x/net/html"
+)
+
+func TestLinkNumber(t *testing.T) {
+	links := []LinkPosition{
+		{Href: "https://a.com", Number: 1},
+		{Href: "https://a.com", Number: 0},
+		{Href: "https://b.com", Number: 2},
+	}
+
+	assert.Equal(t, 1, linkNumber(links, "https://a.com"))
+	assert.Equal(t, 2, linkNumber(links, "https://b.com"))
+	assert.Equal(t, 3, linkNumber(links, "https://c.com"))
+	assert.Equal(t, 1, linkNumber(nil, "https://c.com"))
+}
+
+func TestSuperscript(t *testing.T) {
+	assert.Equal(t, "¹²", superscript("12"))
+	assert.Equal(t, "a⁰", superscript("a0"))
+}
+
+func TestFootnoteNodes(t *testing.T) {
+	body, _ := util.MarkdownToNode([]byte("Some text[^1] and a [link](https://a.com).\n\n[^1]: The note.\n"))
+
+	anchor := func(href string) *html.Node {
+		return findNode(body, func(n *html.Node) bool {
+			h, _ := getAttribute(n, "href")
+			return n.Type == html.ElementNode && n.Data == "a" && h == href
+		})
+	}
+	assert.True(t, isFootnoteRef(anchor("#fn:1")))
+	assert.False(t, isFootnoteRef(anchor("https://a.com")))
+
+	footnotes := findNode(body, isFootnotes)
+	assert.NotNil(t, footnotes)
+	assert.True(t, strings.Contains(util.PlainText(footnotes), "The note."))
+}
+
+func TestLinkContext(t *testing.T) {
+	a := &html.Node{Type: html.ElementNode, Data: "a"}
+	c := RenderingContext{options: &Options{SelectedLink: a}}
+
+	assert.Equal(t, egg.ColorBlue, linkContext(&html.Node{}, c).Canvas.Foreground)
+	selected := linkContext(a, c)
+	assert.Equal(t, egg.ColorBlack, selected.Canvas.Foreground)
+	assert.Equal(t, egg.ColorCyan, selected.Canvas.Background)
+	assert.False(t, isSelectedLink(a, RenderingContext{}))
+}
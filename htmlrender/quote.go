@@ -0,0 +1,75 @@
+package htmlrender
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/model"
+	"github.com/thomgray/notebee/util"
+	"golang.org/x/net/html"
+)
+
+type admonitionStyle struct {
+	title string
+	color egg.Color
+}
+
+var admonitionStyles = map[model.AdmonitionKind]admonitionStyle{
+	model.AdmonitionNote:      {"ⓘ Note", egg.ColorBlue},
+	model.AdmonitionTip:       {"✓ Tip", egg.ColorGreen},
+	model.AdmonitionImportant: {"! Important", egg.ColorMagenta},
+	model.AdmonitionWarning:   {"⚠ Warning", egg.ColorYellow},
+	model.AdmonitionCaution:   {"✖ Caution", egg.ColorRed},
+}
+
+// the last line drawn by a block: blocks leave the cursor a blank line below their content
+func blockLastLine(prc PostRenderingContext, firstLine int) int {
+	last := prc.cursorY - 2
+	if last < firstLine {
+		last = firstLine
+	}
+	return last
+}
+
+// a quote is drawn indented with a bar down its left. The bars of nested quotes stack up
+func renderBlockquote(n *html.Node, rc RenderingContext) PostRenderingContext {
+	startY := rc.cursorY
+	c := rc.setLeftMargin(rc.leftMargin + 2)
+	prc := renderChildren(n, c, rc)
+
+	endY := blockLastLine(prc, startY)
+	for y := startY; y <= endY; y++ {
+		rc.Canvas.DrawString("│", rc.leftMargin, y, egg.ColorBrightBlack, rc.Canvas.Background, rc.Canvas.Attribute)
+	}
+	return prc
+}
+
+// an admonition is drawn in a box in the colour of its kind, with the kind as its title
+func renderAdmonition(n *html.Node, kind model.AdmonitionKind, rc RenderingContext) PostRenderingContext {
+	style := admonitionStyles[kind]
+	left := rc.leftMargin
+	right := rc.rightMargin - 1
+	startY := rc.cursorY
+
+	c := rc.setLeftMargin(left + 2)
+	c.rightMargin = right - 1
+	c.cursorY = startY + 1
+	prc := renderChildren(n, c, rc)
+	endY := blockLastLine(prc, startY+1)
+
+	fg, bg, attr := style.color, rc.Canvas.Background, rc.Canvas.Attribute
+	title := "─ " + style.title + " "
+	titleW := runewidth.StringWidth(title)
+	rc.Canvas.DrawString("┌"+title+strings.Repeat("─", util.MaxInt(right-left-1-titleW, 0))+"┐", left, startY, fg, bg, attr)
+	rc.Canvas.DrawString(style.title, left+3, startY, fg, bg, attr|egg.AttrBold)
+	for y := startY + 1; y <= endY; y++ {
+		rc.Canvas.DrawString("│", left, y, fg, bg, attr)
+		rc.Canvas.DrawString("│", right, y, fg, bg, attr)
+	}
+	rc.Canvas.DrawString("└"+strings.Repeat("─", util.MaxInt(right-left-1, 0))+"┘", left, endY+1, fg, bg, attr)
+
+	prc.cursorY = endY + 1
+	prc.didEndBlock = false
+	return prc.applyBlock(rc)
+}
@@ -7,9 +7,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/model"
 	"golang.org/x/net/html"
 )
 
@@ -56,6 +58,10 @@ type RenderingContext struct {
 	listTier         int
 	listItemIndex    int
 	listType         string
+	marker           *html.Node
+	stripMarker      func(string) string
+	options          *Options
+	result           *Result
 }
 
 func (rc RenderingContext) applyPost(prc PostRenderingContext) RenderingContext {
@@ -116,7 +122,79 @@ func (prc PostRenderingContext) noOp(rc RenderingContext) PostRenderingContext {
 	return prc
 }
 
+// Options - optional rendering behaviour
+type Options struct {
+	// Highlight - finds the ranges of rendered text to highlight
+	Highlight model.Highlighter
+	// LineNumbers - number the lines of code blocks in a gutter
+	LineNumbers bool
+	// NumberLinks - draw links as `text[1]`, with the hrefs listed at the end
+	NumberLinks bool
+	// SelectedLink - the anchor to draw as selected
+	SelectedLink *html.Node
+	// CurrentMatch - the number of the highlighted match to draw as the current one, counting from 1. 0 for none
+	CurrentMatch int
+}
+
+// Result - the outcome of rendering: the height drawn, and where things were drawn
+type Result struct {
+	Height   int
+	Headings []HeadingPosition
+	Links    []LinkPosition
+	// Matches - the highlighted text, in the order it was drawn
+	Matches []TextPosition
+	// ids of the footnotes referred to, and whether the footnotes themselves were drawn
+	footnoteRefs   []string
+	footnotesDrawn bool
+}
+
+// HeadingPosition - the y offset a heading was drawn at
+type HeadingPosition struct {
+	Node *html.Node
+	Y    int
+}
+
+// TextPosition - where a run of text was drawn, and its width
+type TextPosition struct {
+	X     int
+	Y     int
+	Width int
+}
+
+// HeadingY - the y offset a heading node was drawn at
+func (r Result) HeadingY(n *html.Node) (int, bool) {
+	for _, h := range r.Headings {
+		if h.Node == n {
+			return h.Y, true
+		}
+	}
+	return 0, false
+}
+
+// HeadingAt - the last heading drawn at or above the y offset, or nil if there is none
+func (r Result) HeadingAt(y int) *html.Node {
+	var res *html.Node
+	for _, h := range r.Headings {
+		if h.Y > y {
+			break
+		}
+		res = h.Node
+	}
+	return res
+}
+
 func RenderHtml(node *html.Node, c egg.Canvas) int {
+	return RenderNodes([]*html.Node{node}, c)
+}
+
+// RenderNodes - render a sequence of sibling nodes (e.g. a section of a document), returning the height
+func RenderNodes(nodes []*html.Node, c egg.Canvas) int {
+	return Render(nodes, c, Options{}).Height
+}
+
+// Render - render a sequence of sibling nodes with options, reporting what was drawn where
+func Render(nodes []*html.Node, c egg.Canvas, opts Options) Result {
+	result := Result{}
 	rc := RenderingContext{
 		Canvas: c,
 		Box: Box{
@@ -127,9 +205,23 @@ func RenderHtml(node *html.Node, c egg.Canvas) int {
 		cursorX:     0,
 		cursorY:     0,
 		didEndBlock: true, // initially true to prompt
+		options:     &opts,
+		result:      &result,
+	}
+	pc := PostRenderingContext{}.noOp(rc)
+	for _, node := range nodes {
+		pc = renderRecursive(node, rc)
+		rc = rc.applyPost(pc)
+	}
+	if !result.footnotesDrawn && len(result.footnoteRefs) > 0 {
+		pc = renderReferencedFootnotes(nodes, rc)
+		rc = rc.applyPost(pc)
 	}
-	pc := renderRecursive(node, rc)
-	return pc.cursorY
+	if opts.NumberLinks {
+		pc = renderLinkReferences(rc)
+	}
+	result.Height = pc.cursorY
+	return result
 }
 
 func renderRecursive(n *html.Node, c RenderingContext) PostRenderingContext {
@@ -159,6 +251,14 @@ func renderElement(n *html.Node, rc RenderingContext) PostRenderingContext {
 		return renderHeading(n, c)
 	case "hr":
 		return renderHr(n, c)
+	case "table":
+		return renderTable(n, c)
+	case "blockquote":
+		if marker, kind, ok := model.AdmonitionMarker(n); ok {
+			c.marker, c.stripMarker = marker, model.StripAdmonitionMarker
+			return renderAdmonition(n, kind, c)
+		}
+		return renderBlockquote(n, c)
 	// check the tag for some simple rendering rules
 	case "code":
 		c.Canvas.Foreground = egg.ColorWhite
@@ -180,19 +280,32 @@ func renderElement(n *html.Node, rc RenderingContext) PostRenderingContext {
 		c = c.setLeftMargin(c.leftMargin + 2)
 	case "li":
 		var liStr string
+		liFg := egg.ColorMagenta
 		switch c.listType {
 		case "ol":
 			liStr = strconv.Itoa(c.listItemIndex+1) + "."
 		default:
 			liStr = " •"
 		}
-		c.Canvas.DrawString(liStr, c.leftMargin, c.cursorY, egg.ColorMagenta, c.Canvas.Background, c.Canvas.Attribute)
+		if marker, done, ok := model.TaskMarker(n); ok {
+			c.marker, c.stripMarker = marker, model.StripTaskMarker
+			liStr, liFg = " ☐", egg.ColorYellow
+			if done {
+				liStr, liFg = " ☑", egg.ColorGreen
+				c.Canvas.Foreground = egg.ColorBrightBlack
+			}
+		}
+		c.Canvas.DrawString(liStr, c.leftMargin, c.cursorY, liFg, c.Canvas.Background, c.Canvas.Attribute)
 		c = c.setLeftMargin(c.leftMargin + 3)
 		c.listItemIndex++
 	case "del":
 		c.strikethrough = true
 	case "a":
 		return renderAnchor(n, c)
+	case "div":
+		if isFootnotes(n) {
+			return renderFootnotes(n, c)
+		}
 	}
 
 	return renderChildren(n, c, rc)
@@ -217,6 +330,10 @@ func renderHeading(n *html.Node, rc RenderingContext) PostRenderingContext {
 		hval = 6
 	}
 
+	if rc.result != nil {
+		rc.result.Headings = append(rc.result.Headings, HeadingPosition{n, rc.cursorY})
+	}
+
 	padW := 7 - hval
 	pre := strings.Repeat("│", padW)
 	underPre := "└" + strings.Repeat("┴", padW-1)
@@ -282,66 +399,88 @@ func renderList(n *html.Node, rc RenderingContext) PostRenderingContext {
 
 func renderAnchor(n *html.Node, c RenderingContext) PostRenderingContext {
 	if href, err := getAttribute(n, "href"); err == nil {
+		if isFootnoteRef(n) {
+			return renderFootnoteRef(n, c)
+		}
+		x, y := c.cursorX, c.cursorY
+		var prc PostRenderingContext
+		number := 0
 		nodeText, nodeTextErr := nodeText(n)
-		log.Printf("href= %s", nodeText)
 		if nodeTextErr == nil && nodeText == href {
 			// href==text so it is a simple one
-			c.Canvas.Foreground = egg.ColorBlue
-			return renderChildren(n, c, c)
+			linkC := linkContext(n, c)
+			prc = renderChildren(n, linkC, c)
+		} else if c.options != nil && c.options.NumberLinks {
+			prc, number = renderNumberedAnchor(n, href, c)
+		} else if isSelectedLink(n, c) {
+			prc = renderAnchorWithHref(n, href, linkContext(n, c))
+		} else {
+			prc = renderAnchorWithHref(n, href, c)
 		}
+		if c.result != nil {
+			c.result.Links = append(c.result.Links, LinkPosition{n, href, number, x, y})
+		}
+		return prc
+	}
 
-		thisC := c.copy()
+	// anchor without an href? so render the content as normal then
+	return renderChildren(n, c, c)
+}
 
-		prc := renderChildren(n, c, thisC)
-		prc.cursorX++
-		maxW := thisC.rightMargin - thisC.leftMargin
-		remainingW := thisC.rightMargin - prc.cursorX
-		// now we should draw the href, making sure to wrap lines if needed
-		hrefWithBracket := fmt.Sprintf("(%s)", href)
+// render the link text followed by its href, e.g. `text @(https://...)`
+func renderAnchorWithHref(n *html.Node, href string, c RenderingContext) PostRenderingContext {
+	thisC := c.copy()
 
-		if remainingW < 1 {
+	prc := renderChildren(n, c, thisC)
+	prc.cursorX++
+	maxW := thisC.rightMargin - thisC.leftMargin
+	remainingW := thisC.rightMargin - prc.cursorX
+	// now we should draw the href, making sure to wrap lines if needed
+	hrefWithBracket := fmt.Sprintf("(%s)", href)
+
+	if remainingW < 1 {
+		prc.cursorX = thisC.leftMargin
+		prc.cursorY++
+	}
+	// draw the @...
+	c.Canvas.DrawString("@", prc.cursorX, prc.cursorY, egg.ColorMagenta, c.Canvas.Background, c.Canvas.Attribute)
+	prc.cursorX++
+
+	toDraw := hrefWithBracket
+	keepDrawing := true
+
+	openingBracketX := prc.cursorX
+	openingBracketY := prc.cursorY
+	for keepDrawing {
+		slice, remainder, done := sliceForLine(toDraw, thisC.rightMargin-prc.cursorX, maxW)
+		log.Println("just keep drawing", slice)
+		toDraw = remainder
+
+		thisC.Canvas.DrawString(slice, prc.cursorX, prc.cursorY, egg.ColorBlue, c.Canvas.Background, c.Canvas.Attribute)
+		if done {
+			prc.cursorX += runewidth.StringWidth(slice)
+		} else {
 			prc.cursorX = thisC.leftMargin
 			prc.cursorY++
 		}
-		// draw the @...
-		c.Canvas.DrawString("@", prc.cursorX, prc.cursorY, egg.ColorMagenta, c.Canvas.Background, c.Canvas.Attribute)
-		prc.cursorX++
-
-		toDraw := hrefWithBracket
-		keepDrawing := true
-
-		openingBracketX := prc.cursorX
-		openingBracketY := prc.cursorY
-		for keepDrawing {
-			slice, remainder, done := sliceForLine(toDraw, thisC.rightMargin-prc.cursorX, maxW)
-			log.Println("just keep drawing", slice)
-			toDraw = remainder
-
-			thisC.Canvas.DrawString(slice, prc.cursorX, prc.cursorY, egg.ColorBlue, c.Canvas.Background, c.Canvas.Attribute)
-			if done {
-				prc.cursorX += runewidth.StringWidth(slice)
-			} else {
-				prc.cursorX = thisC.leftMargin
-				prc.cursorY++
-			}
-			keepDrawing = !done
-		}
-
-		// just need to tweak the bracket colour by re-drawing them...
-		thisC.Canvas.DrawRune('(', openingBracketX, openingBracketY, egg.ColorMagenta, thisC.Canvas.Background, thisC.copy().Canvas.Attribute)
-		thisC.Canvas.DrawRune(')', prc.cursorX-1, prc.cursorY, egg.ColorMagenta, thisC.Canvas.Background, thisC.copy().Canvas.Attribute)
-		return prc
+		keepDrawing = !done
 	}
 
-	// anchor without an href? so render the content as normal then
-	return renderChildren(n, c, c)
+	// just need to tweak the bracket colour by re-drawing them...
+	thisC.Canvas.DrawRune('(', openingBracketX, openingBracketY, egg.ColorMagenta, thisC.Canvas.Background, thisC.copy().Canvas.Attribute)
+	thisC.Canvas.DrawRune(')', prc.cursorX-1, prc.cursorY, egg.ColorMagenta, thisC.Canvas.Background, thisC.copy().Canvas.Attribute)
+	return prc
 }
 
 func renderText(n *html.Node, c RenderingContext) PostRenderingContext {
 	if c.preformatted {
 		return renerTextPreformatted(n, c)
 	}
-	normalS := normaliseText(n.Data)
+	text := n.Data
+	if n == c.marker {
+		text = c.stripMarker(text)
+	}
+	normalS := normaliseText(text)
 	startsWithWs := strings.HasPrefix(normalS, " ")
 	endsWithWs := strings.HasSuffix(normalS, " ")
 	if c.endsInWhitespace && startsWithWs {
@@ -372,7 +511,8 @@ func renderText(n *html.Node, c RenderingContext) PostRenderingContext {
 	for keepWriting {
 		slice, remainder, finised := sliceForLine(normalS, lineL, boxW)
 		normalS = remainder
-		c.Canvas.DrawString2(slice, c.cursorX, c.cursorY)
+		drawText(c.Canvas, slice, c.cursorX, c.cursorY, c.Canvas.Foreground, c.Canvas.Background, c.Canvas.Attribute)
+		drawHighlights(slice, c.cursorX, c.cursorY, c)
 		if !finised {
 			// new line
 			c.cursorX = c.leftMargin
@@ -399,19 +539,47 @@ func renerTextPreformatted(n *html.Node, c RenderingContext) PostRenderingContex
 	firstLineIsBlank := blankR.Match([]byte(lines[0]))
 	lastLineIsBlank := blankR.Match([]byte(lines[len(lines)-1]))
 
+	var tokens [][]Token
+	if lexer := LexerFor(codeLanguage(n)); lexer != nil {
+		tokens = lineTokens(s, lexer.Tokenise(s))
+	}
+
+	// the trailing newline of a code block isn't a line of code
+	numbered := len(lines)
+	if numbered > 1 && lines[numbered-1] == "" {
+		numbered--
+	}
+	gutterW := 0
+	if c.options != nil && c.options.LineNumbers {
+		gutterW = len(strconv.Itoa(numbered)) + 1
+	}
+
 	if !firstLineIsBlank {
 		pad := strings.Repeat("\000", boxW)
 		c.Canvas.DrawString2(pad, c.leftMargin, c.cursorY)
 		c.cursorY++
 	}
 
-	for _, l := range lines {
-		padL := boxW - runewidth.StringWidth(l)
+	for i, l := range lines {
+		padL := boxW - gutterW - runewidth.StringWidth(l)
 		if padL < 0 {
 			padL = 0
 		}
 		pad := strings.Repeat("\000", padL)
-		c.Canvas.DrawString2(l+pad, c.leftMargin, c.cursorY)
+		if gutterW > 0 {
+			num := strings.Repeat("\000", gutterW)
+			if i < numbered {
+				num = fmt.Sprintf("%*d ", gutterW-1, i+1)
+			}
+			c.Canvas.DrawString(num, c.leftMargin, c.cursorY, egg.ColorBrightBlack, c.Canvas.Background, c.Canvas.Attribute)
+		}
+		x := c.leftMargin + gutterW
+		drawText(c.Canvas, l, x, c.cursorY, c.Canvas.Foreground, c.Canvas.Background, c.Canvas.Attribute)
+		c.Canvas.DrawString2(pad, x+runewidth.StringWidth(l), c.cursorY)
+		if tokens != nil {
+			drawTokens(l, tokens[i], x, c.cursorY, c)
+		}
+		drawHighlights(l, x, c.cursorY, c)
 		c.cursorY++
 	}
 
@@ -427,13 +595,58 @@ func renerTextPreformatted(n *html.Node, c RenderingContext) PostRenderingContex
 	return prc
 }
 
+// re-draw the tokens of a line of code that was just drawn at x, y in their syntax colours
+func drawTokens(line string, tokens []Token, x, y int, c RenderingContext) {
+	for _, t := range tokens {
+		fg, ok := SyntaxColors[t.Kind]
+		if !ok {
+			continue
+		}
+		tx := x + runewidth.StringWidth(line[:t.Start])
+		drawText(c.Canvas, line[t.Start:t.End], tx, y, fg, c.Canvas.Background, c.Canvas.Attribute)
+	}
+}
+
+// re-draw any words matching the highlight terms in a string that was just drawn at x, y
+func drawHighlights(s string, x, y int, c RenderingContext) {
+	if c.options == nil || c.options.Highlight == nil {
+		return
+	}
+	for _, m := range c.options.Highlight(s) {
+		mx := x + runewidth.StringWidth(s[:m.Start])
+		bg := egg.ColorYellow
+		if c.result != nil {
+			c.result.Matches = append(c.result.Matches, TextPosition{mx, y, runewidth.StringWidth(s[m.Start:m.End])})
+			if len(c.result.Matches) == c.options.CurrentMatch {
+				bg = egg.ColorMagenta
+			}
+		}
+		drawText(c.Canvas, s[m.Start:m.End], mx, y, egg.ColorBlack, bg, c.Canvas.Attribute)
+	}
+}
+
+// drawText - draw a string a rune at a time, each at the column runewidth gives it, so that wide characters
+// line up with widths measured by runewidth. Zero width runes can't be combined on the canvas, so are left out
+func drawText(c egg.Canvas, s string, x, y int, fg, bg egg.Color, attr egg.Attribute) {
+	for _, r := range s {
+		w := runewidth.RuneWidth(r)
+		if w == 0 {
+			continue
+		}
+		c.DrawRune(r, x, y, fg, bg, attr)
+		x += w
+	}
+}
+
+// strikethroughString - follow every rune with a combining strikethrough
 func strikethroughString(s string) string {
-	//todo - interleave with strikethrough
-	var out string = ""
+	var sb strings.Builder
+	sb.Grow(len(s) * (1 + utf8.RuneLen(strikethroughCombining)))
 	for _, c := range s {
-		out = fmt.Sprintf("%s%c%c", out, c, strikethroughCombining)
+		sb.WriteRune(c)
+		sb.WriteRune(strikethroughCombining)
 	}
-	return out
+	return sb.String()
 }
 
 func renderChildren(n *html.Node, c RenderingContext, thisC RenderingContext) PostRenderingContext {
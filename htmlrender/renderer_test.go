@@ -0,0 +1,37 @@
+package htmlrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/model"
+	"golang.org/x/net/html"
+)
+
+func TestHeadingAt(t *testing.T) {
+	h1, h2 := &html.Node{Data: "h1"}, &html.Node{Data: "h2"}
+	r := Result{Headings: []HeadingPosition{{h1, 0}, {h2, 10}}}
+
+	assert.Equal(t, h1, r.HeadingAt(0))
+	assert.Equal(t, h1, r.HeadingAt(9))
+	assert.Equal(t, h2, r.HeadingAt(10))
+	assert.Equal(t, h2, r.HeadingAt(50))
+	assert.Nil(t, r.HeadingAt(-1))
+	assert.Nil(t, Result{}.HeadingAt(5))
+}
+
+func TestHighlightPositionsWithWideCharacters(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader(`<p>日本語 pod</p><pre><code>名前 := pod</code></pre>`))
+	// an empty viewport, so nothing is drawn to the screen
+	canvas := egg.Canvas{Bounds: egg.MakeBounds(0, 0, 80, 0), ViewPort: &egg.Bounds{}}
+
+	result := Render([]*html.Node{node}, canvas, Options{Highlight: model.TermHighlighter([]string{"pod"})})
+	assert.Equal(t, 2, len(result.Matches))
+	// each of the CJK characters takes two columns
+	assert.Equal(t, 7, result.Matches[0].X)
+	assert.Equal(t, 3, result.Matches[0].Width)
+	assert.Equal(t, 8, result.Matches[1].X)
+	assert.Equal(t, 3, result.Matches[1].Width)
+}
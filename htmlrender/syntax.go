@@ -0,0 +1,290 @@
+package htmlrender
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thomgray/egg"
+	"golang.org/x/net/html"
+)
+
+// TokenKind - the syntactic role of a token of code
+type TokenKind uint8
+
+const (
+	TokenText TokenKind = iota
+	TokenKeyword
+	TokenBuiltin
+	TokenString
+	TokenNumber
+	TokenComment
+	TokenKey
+	TokenVariable
+)
+
+// Token - a span of source code, by byte offset
+type Token struct {
+	Kind  TokenKind
+	Start int
+	End   int
+}
+
+// Lexer - splits source code into tokens. Text between tokens is drawn plainly
+type Lexer interface {
+	Tokenise(src string) []Token
+}
+
+// SyntaxColors - the colour each kind of token is drawn in
+var SyntaxColors = map[TokenKind]egg.Color{
+	TokenKeyword:  egg.ColorMagenta,
+	TokenBuiltin:  egg.ColorCyan,
+	TokenString:   egg.ColorGreen,
+	TokenNumber:   egg.ColorYellow,
+	TokenComment:  egg.ColorBrightBlack,
+	TokenKey:      egg.ColorBrightBlue,
+	TokenVariable: egg.ColorRed,
+}
+
+var lexers = make(map[string]Lexer)
+
+// RegisterLexer - use the lexer for code blocks in any of the languages, e.g. "go" for ```go
+func RegisterLexer(l Lexer, languages ...string) {
+	for _, lang := range languages {
+		lexers[strings.ToLower(lang)] = l
+	}
+}
+
+// LexerFor - the lexer registered for a language, or nil
+func LexerFor(language string) Lexer {
+	return lexers[strings.ToLower(language)]
+}
+
+// the language of a code block: blackfriday puts it in the class of the code element as `language-go`
+func codeLanguage(n *html.Node) string {
+	for p := n; p != nil; p = p.Parent {
+		if p.Type != html.ElementNode || p.Data != "code" {
+			continue
+		}
+		class, _ := getAttribute(p, "class")
+		for _, c := range strings.Fields(class) {
+			if strings.HasPrefix(c, "language-") {
+				return strings.TrimPrefix(c, "language-")
+			}
+		}
+	}
+	return ""
+}
+
+// lexRule - a token matched by a regular expression anchored at the current position
+type lexRule struct {
+	kind    TokenKind
+	pattern *regexp.Regexp
+	// afterSpace - only match at the start of the source or after whitespace
+	afterSpace bool
+	// lineStart - only match after indentation (and yaml list dashes) at the start of a line
+	lineStart bool
+	// followedBy - only match if this is the next character after any spaces
+	followedBy byte
+}
+
+func rule(kind TokenKind, pattern string) lexRule {
+	return lexRule{kind: kind, pattern: regexp.MustCompile(`^(?:` + pattern + `)`)}
+}
+
+// ruleLexer - tries each rule in turn at each position, otherwise consumes a word and looks it up in the keywords
+type ruleLexer struct {
+	rules      []lexRule
+	keywords   map[string]TokenKind
+	ignoreCase bool
+}
+
+var identifierRegex = regexp.MustCompile(`^[\pL_][\pL\pN_]*`)
+
+func (rl ruleLexer) Tokenise(src string) []Token {
+	res := make([]Token, 0)
+	for i := 0; i < len(src); {
+		if tok, ok := rl.matchRule(src, i); ok {
+			res = append(res, tok)
+			i = tok.End
+			continue
+		}
+		if word := identifierRegex.FindString(src[i:]); word != "" {
+			if rl.ignoreCase {
+				word = strings.ToLower(word)
+			}
+			if kind, ok := rl.keywords[word]; ok {
+				res = append(res, Token{kind, i, i + len(word)})
+			}
+			i += len(word)
+			continue
+		}
+		i++
+	}
+	return res
+}
+
+func (rl ruleLexer) matchRule(src string, i int) (Token, bool) {
+	for _, r := range rl.rules {
+		if r.afterSpace && i > 0 && !strings.ContainsRune(" \t\n", rune(src[i-1])) {
+			continue
+		}
+		if r.lineStart && !atLineStart(src, i) {
+			continue
+		}
+		loc := r.pattern.FindStringIndex(src[i:])
+		if loc == nil || loc[1] == 0 {
+			continue
+		}
+		end := i + loc[1]
+		if r.followedBy != 0 {
+			next := strings.TrimLeft(src[end:], " \t")
+			if next == "" || next[0] != r.followedBy {
+				continue
+			}
+		}
+		return Token{r.kind, i, end}, true
+	}
+	return Token{}, false
+}
+
+func atLineStart(src string, i int) bool {
+	lineBegin := strings.LastIndex(src[:i], "\n") + 1
+	return strings.Trim(src[lineBegin:i], " \t-") == ""
+}
+
+func keywordSet(kind TokenKind, words string, into map[string]TokenKind) map[string]TokenKind {
+	if into == nil {
+		into = make(map[string]TokenKind)
+	}
+	for _, w := range strings.Fields(words) {
+		into[w] = kind
+	}
+	return into
+}
+
+const (
+	numberPattern      = `0[xX][0-9a-fA-F_]+|\d[\d_]*(\.\d+)?([eE][+-]?\d+)?`
+	doubleQuotePattern = `"(\\.|[^"\\\n])*"`
+	singleQuotePattern = `'(\\.|[^'\\\n])*'`
+)
+
+func init() {
+	goKeywords := keywordSet(TokenKeyword, `break case chan const continue default defer else fallthrough for func go
+		goto if import interface map package range return select struct switch type var`, nil)
+	RegisterLexer(ruleLexer{
+		rules: []lexRule{
+			rule(TokenComment, `//[^\n]*`),
+			rule(TokenComment, `/\*[\s\S]*?\*/`),
+			rule(TokenString, doubleQuotePattern),
+			rule(TokenString, "`[^`]*`"),
+			rule(TokenString, singleQuotePattern),
+			rule(TokenNumber, numberPattern),
+		},
+		keywords: keywordSet(TokenBuiltin, `bool byte complex64 complex128 error float32 float64 int int8 int16 int32
+			int64 rune string uint uint8 uint16 uint32 uint64 uintptr true false iota nil append cap close complex copy
+			delete imag len make new panic print println real recover`, goKeywords),
+	}, "go", "golang")
+
+	shellKeywords := keywordSet(TokenKeyword, `if then else elif fi for while until do done case esac function in
+		return export local readonly unset select`, nil)
+	RegisterLexer(ruleLexer{
+		rules: []lexRule{
+			{kind: TokenComment, pattern: regexp.MustCompile(`^#[^\n]*`), afterSpace: true},
+			rule(TokenString, `"(\\.|[^"\\])*"`),
+			rule(TokenString, `'[^']*'`),
+			rule(TokenVariable, `\$\{[^}\n]*\}|\$[\pL_][\pL\pN_]*|\$[@#?$!*0-9-]`),
+			{kind: TokenBuiltin, pattern: regexp.MustCompile(`^--?[\pL\pN][\pL\pN_-]*`), afterSpace: true},
+			{kind: TokenNumber, pattern: regexp.MustCompile(`^\d+\b`), afterSpace: true},
+		},
+		keywords: keywordSet(TokenBuiltin, `echo cd pwd source exit set test read printf eval exec shift trap alias
+			sudo`, shellKeywords),
+	}, "sh", "shell", "bash", "zsh", "console")
+
+	RegisterLexer(ruleLexer{
+		rules: []lexRule{
+			{kind: TokenKey, pattern: regexp.MustCompile(`^"(\\.|[^"\\\n])*"`), followedBy: ':'},
+			rule(TokenString, doubleQuotePattern),
+			rule(TokenNumber, `-?(`+numberPattern+`)`),
+		},
+		keywords: keywordSet(TokenKeyword, `true false null`, nil),
+	}, "json")
+
+	RegisterLexer(ruleLexer{
+		rules: []lexRule{
+			{kind: TokenComment, pattern: regexp.MustCompile(`^#[^\n]*`), afterSpace: true},
+			{kind: TokenKeyword, pattern: regexp.MustCompile(`^(---|\.\.\.)`), lineStart: true},
+			{kind: TokenKey, pattern: regexp.MustCompile(`^("[^"\n]*"|'[^'\n]*'|[^\s:#'"-][^:\n#]*)`), lineStart: true, followedBy: ':'},
+			rule(TokenString, doubleQuotePattern),
+			rule(TokenString, singleQuotePattern),
+			rule(TokenVariable, `[&*][\pL\pN_-]+`),
+			{kind: TokenNumber, pattern: regexp.MustCompile(`^-?(` + numberPattern + `)\b`), afterSpace: true},
+		},
+		keywords: keywordSet(TokenKeyword, `true false null yes no on off`, nil),
+	}, "yaml", "yml")
+
+	RegisterLexer(ruleLexer{
+		rules: []lexRule{
+			rule(TokenComment, `--[^\n]*`),
+			rule(TokenComment, `/\*[\s\S]*?\*/`),
+			rule(TokenString, `'([^']|'')*'`),
+			rule(TokenKey, `"[^"\n]*"`),
+			rule(TokenNumber, numberPattern),
+		},
+		ignoreCase: true,
+		keywords: keywordSet(TokenKeyword, `select from where and or not insert into values update set delete create
+			table drop alter add index view join inner left right outer full on as group by order having limit offset
+			distinct union all case when then else end is null like in between exists primary key foreign references
+			default asc desc with returning begin commit rollback transaction`,
+			keywordSet(TokenBuiltin, `count sum avg min max coalesce now cast int integer bigint varchar text boolean
+				date timestamp serial`, nil)),
+	}, "sql", "psql", "mysql")
+
+	pythonKeywords := keywordSet(TokenKeyword, `and as assert async await break class continue def del elif else except
+		finally for from global if import in is lambda nonlocal not or pass raise return try while with yield True
+		False None`, nil)
+	RegisterLexer(ruleLexer{
+		rules: []lexRule{
+			rule(TokenComment, `#[^\n]*`),
+			rule(TokenString, `[rRbBuUfF]{0,2}("""[\s\S]*?"""|'''[\s\S]*?''')`),
+			rule(TokenString, `[rRbBuUfF]{0,2}(`+doubleQuotePattern+`|`+singleQuotePattern+`)`),
+			rule(TokenVariable, `@[\pL_][\pL\pN_.]*`),
+			rule(TokenNumber, numberPattern),
+		},
+		keywords: keywordSet(TokenBuiltin, `print len range open str int float list dict set tuple bool type isinstance
+			enumerate zip map filter sorted super self object`, pythonKeywords),
+	}, "python", "py")
+}
+
+// lineTokens - the tokens in each line of the source, with offsets relative to the start of the line.
+// Tokens spanning lines are split between them
+func lineTokens(src string, tokens []Token) [][]Token {
+	lines := strings.Split(src, "\n")
+	res := make([][]Token, len(lines))
+	sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].Start < tokens[j].Start })
+
+	lineStart := 0
+	t := 0
+	for i, line := range lines {
+		lineEnd := lineStart + len(line)
+		for ; t < len(tokens) && tokens[t].Start < lineEnd+1; t++ {
+			tok := tokens[t]
+			start, end := tok.Start, tok.End
+			if start < lineStart {
+				start = lineStart
+			}
+			if end > lineEnd {
+				end = lineEnd
+			}
+			if end > start {
+				res[i] = append(res[i], Token{tok.Kind, start - lineStart, end - lineStart})
+			}
+			if tok.End > lineEnd+1 {
+				// continues onto the next line
+				break
+			}
+		}
+		lineStart = lineEnd + 1
+	}
+	return res
+}
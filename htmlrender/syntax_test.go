@@ -0,0 +1,106 @@
+package htmlrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+type lexed struct {
+	Kind TokenKind
+	Text string
+}
+
+func lex(lang, src string) []lexed {
+	res := make([]lexed, 0)
+	for _, t := range LexerFor(lang).Tokenise(src) {
+		res = append(res, lexed{t.Kind, src[t.Start:t.End]})
+	}
+	return res
+}
+
+func TestLexGo(t *testing.T) {
+	assert.Equal(t, []lexed{
+		{TokenKeyword, "func"},
+		{TokenBuiltin, "len"},
+		{TokenString, `"a \"b\""`},
+		{TokenNumber, "0x1F"},
+		{TokenComment, "// done"},
+	}, lex("go", `func f() { len("a \"b\"") + 0x1F } // done`))
+	assert.Nil(t, LexerFor("brainfuck"))
+	assert.Equal(t, LexerFor("go"), LexerFor("Golang"))
+}
+
+func TestLexShell(t *testing.T) {
+	assert.Equal(t, []lexed{
+		{TokenBuiltin, "echo"},
+		{TokenString, `"$HOME/bin"`},
+		{TokenBuiltin, "--all"},
+		{TokenVariable, "${NAME}"},
+		{TokenComment, "# note"},
+	}, lex("bash", `echo "$HOME/bin" kubectl-get --all ${NAME}#x # note`))
+}
+
+func TestLexJSONAndYAML(t *testing.T) {
+	assert.Equal(t, []lexed{
+		{TokenKey, `"name"`},
+		{TokenString, `"notebee"`},
+		{TokenKey, `"tags"`},
+		{TokenNumber, "-1.5"},
+		{TokenKeyword, "null"},
+	}, lex("json", `{"name": "notebee", "tags" : [-1.5, null]}`))
+
+	assert.Equal(t, []lexed{
+		{TokenKeyword, "---"},
+		{TokenKey, "name"},
+		{TokenString, `"web"`},
+		{TokenKey, "replicas"},
+		{TokenNumber, "3"},
+		{TokenComment, "# scale"},
+		{TokenKey, "image"},
+		{TokenKeyword, "true"},
+		{TokenKey, "url"},
+	}, lex("yaml", "---\nname: \"web\"\nreplicas: 3 # scale\n- image: true\nurl: http://x"))
+}
+
+func TestLexSQLAndPython(t *testing.T) {
+	assert.Equal(t, []lexed{
+		{TokenKeyword, "SELECT"},
+		{TokenBuiltin, "count"},
+		{TokenKeyword, "FROM"},
+		{TokenKeyword, "where"},
+		{TokenString, "'it''s'"},
+		{TokenComment, "-- all"},
+	}, lex("sql", "SELECT count(*) FROM t where x = 'it''s' -- all"))
+
+	assert.Equal(t, []lexed{
+		{TokenVariable, "@cache"},
+		{TokenKeyword, "def"},
+		{TokenKeyword, "return"},
+		{TokenString, `f"{x}"`},
+		{TokenString, "'''doc\nstring'''"},
+		{TokenKeyword, "or"},
+		{TokenKeyword, "None"},
+	}, lex("python", "@cache\ndef f(x): return f\"{x}\" + '''doc\nstring''' or None"))
+}
+
+func TestLineTokens(t *testing.T) {
+	src := "a /* one\ntwo */ b\nc"
+	tokens := lineTokens(src, LexerFor("go").Tokenise(src))
+
+	assert.Equal(t, [][]Token{
+		{{TokenComment, 2, 8}},
+		{{TokenComment, 0, 6}},
+		nil,
+	}, tokens)
+}
+
+func TestCodeLanguage(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader(`<pre><code class="language-go">x := 1</code></pre>`))
+	var text *html.Node
+	for text = node; text.FirstChild != nil; text = text.LastChild {
+	}
+	assert.Equal(t, "go", codeLanguage(text))
+}
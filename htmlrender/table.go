@@ -0,0 +1,408 @@
+package htmlrender
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/thomgray/egg"
+	"golang.org/x/net/html"
+)
+
+// tableStyle - how a word in a table cell is drawn
+type tableStyle struct {
+	fg   egg.Color
+	bg   egg.Color
+	attr egg.Attribute
+}
+
+// tableWord - a word of a cell's content. Words are separated by a space when space is set,
+// otherwise they run on from the previous word (e.g. punctuation after emphasis)
+type tableWord struct {
+	text      string
+	style     tableStyle
+	space     bool
+	lineBreak bool
+	// the anchor the word is the text of, and the link's number if links are numbered
+	link   *html.Node
+	href   string
+	number int
+}
+
+type tableCell struct {
+	words  []tableWord
+	align  string
+	header bool
+}
+
+type tableRow struct {
+	cells  []tableCell
+	header bool
+}
+
+// width of each cell's padding either side of its content
+const tableCellPadding = 1
+
+func renderTable(n *html.Node, rc RenderingContext) PostRenderingContext {
+	base := tableStyle{rc.Canvas.Foreground, rc.Canvas.Background, rc.Canvas.Attribute}
+	rows := parseTableRows(n, base)
+	if rc.options != nil && rc.options.NumberLinks && rc.result != nil {
+		numberTableLinks(rows, rc.result.Links)
+	}
+	prc := PostRenderingContext{}.noOp(rc)
+	if len(rows) == 0 {
+		return prc
+	}
+
+	ncols := tableColumnCount(rows)
+	// borders between and either side of the columns, plus padding
+	chrome := ncols + 1 + 2*tableCellPadding*ncols
+	widths := tableColumnWidths(rows, ncols, rc.rightMargin-rc.leftMargin-chrome)
+
+	y := rc.cursorY
+	drawTableBorder(rc, y, widths, "┌", "┬", "┐", "─")
+	y++
+	for i, row := range rows {
+		y = drawTableRow(rc, y, row, widths)
+		if row.header && i < len(rows)-1 && !rows[i+1].header {
+			drawTableBorder(rc, y, widths, "╞", "╪", "╡", "═")
+			y++
+		}
+	}
+	drawTableBorder(rc, y, widths, "└", "┴", "┘", "─")
+
+	prc.cursorY = y
+	prc.didEndBlock = false
+	return prc.applyBlock(rc)
+}
+
+func drawTableBorder(rc RenderingContext, y int, widths []int, left, middle, right, line string) {
+	var sb strings.Builder
+	sb.WriteString(left)
+	for i, w := range widths {
+		if i > 0 {
+			sb.WriteString(middle)
+		}
+		sb.WriteString(strings.Repeat(line, w+2*tableCellPadding))
+	}
+	sb.WriteString(right)
+	rc.Canvas.DrawString(sb.String(), rc.leftMargin, y, egg.ColorBlue, rc.Canvas.Background, rc.Canvas.Attribute)
+}
+
+// draw a row starting at y, its cells wrapped to the column widths. Returns the y after the row
+func drawTableRow(rc RenderingContext, y int, row tableRow, widths []int) int {
+	lines := make([][][]tableWord, len(widths))
+	height := 1
+	for i := range widths {
+		if i < len(row.cells) {
+			lines[i] = wrapTableCell(row.cells[i].words, widths[i])
+		}
+		if len(lines[i]) > height {
+			height = len(lines[i])
+		}
+	}
+
+	for dy := 0; dy < height; dy++ {
+		x := rc.leftMargin
+		for i, w := range widths {
+			rc.Canvas.DrawString("│", x, y+dy, egg.ColorBlue, rc.Canvas.Background, rc.Canvas.Attribute)
+			x += 1 + tableCellPadding
+			if dy < len(lines[i]) {
+				align := ""
+				if i < len(row.cells) {
+					align = row.cells[i].align
+				}
+				line := lines[i][dy]
+				drawTableLine(rc, x+tableAlignOffset(align, tableLineWidth(line), w), y+dy, line)
+			}
+			x += w + tableCellPadding
+		}
+		rc.Canvas.DrawString("│", x, y+dy, egg.ColorBlue, rc.Canvas.Background, rc.Canvas.Attribute)
+	}
+	return y + height
+}
+
+func drawTableLine(rc RenderingContext, x, y int, line []tableWord) {
+	startX := x
+	var sb strings.Builder
+	for i, word := range line {
+		if i > 0 && word.space {
+			sb.WriteString(" ")
+			x++
+		}
+		style := word.style
+		if word.link != nil {
+			if isSelectedLink(word.link, rc) {
+				style.fg, style.bg = egg.ColorBlack, egg.ColorCyan
+			}
+			recordTableLink(word, x, y, rc)
+		}
+		drawText(rc.Canvas, word.text, x, y, style.fg, style.bg, style.attr)
+		sb.WriteString(word.text)
+		x += runewidth.StringWidth(word.text)
+	}
+	drawHighlights(sb.String(), startX, y, rc)
+}
+
+// record where a link in a table was drawn, at its first word
+func recordTableLink(word tableWord, x, y int, rc RenderingContext) {
+	if rc.result == nil {
+		return
+	}
+	for _, l := range rc.result.Links {
+		if l.Node == word.link {
+			return
+		}
+	}
+	rc.result.Links = append(rc.result.Links, LinkPosition{word.link, word.href, word.number, x, y})
+}
+
+// numberTableLinks - number the links in the cells, following on from the links already drawn,
+// and put the number after each link's text, e.g. `text[3]`
+func numberTableLinks(rows []tableRow, links []LinkPosition) {
+	numbered := append([]LinkPosition{}, links...)
+	for _, row := range rows {
+		for i := range row.cells {
+			words := make([]tableWord, 0, len(row.cells[i].words))
+			for j, word := range row.cells[i].words {
+				if word.link != nil && word.number == 0 {
+					if text, err := nodeText(word.link); err != nil || text != word.href {
+						word.number = linkNumber(numbered, word.href)
+						numbered = append(numbered, LinkPosition{Node: word.link, Href: word.href, Number: word.number})
+					}
+				}
+				words = append(words, word)
+				last := j == len(row.cells[i].words)-1 || row.cells[i].words[j+1].link != word.link
+				if word.number > 0 && last {
+					style := word.style
+					style.fg = egg.ColorMagenta
+					words = append(words, tableWord{text: "[" + strconv.Itoa(word.number) + "]", style: style})
+				}
+			}
+			row.cells[i].words = words
+		}
+	}
+}
+
+// parseTableRows - the rows of a table in order, from the thead, tbody and tfoot, or directly within the table
+func parseTableRows(n *html.Node, base tableStyle) []tableRow {
+	rows := make([]tableRow, 0)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.Data {
+		case "thead", "tbody", "tfoot":
+			rows = append(rows, parseTableRows(c, base)...)
+		case "tr":
+			rows = append(rows, parseTableRow(c, base))
+		}
+	}
+	return rows
+}
+
+func parseTableRow(tr *html.Node, base tableStyle) tableRow {
+	row := tableRow{cells: make([]tableCell, 0), header: true}
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+			continue
+		}
+		cell := tableCell{header: c.Data == "th"}
+		cell.align, _ = getAttribute(c, "align")
+		style := base
+		if cell.header {
+			style.attr |= egg.AttrBold
+		}
+		collector := tableWordCollector{}
+		collector.collect(c, style)
+		cell.words = collector.words
+
+		row.cells = append(row.cells, cell)
+		row.header = row.header && cell.header
+	}
+	row.header = row.header && len(row.cells) > 0
+	return row
+}
+
+type tableWordCollector struct {
+	words        []tableWord
+	pendingSpace bool
+	// the anchor and href of the text being collected, if it is a link
+	link *html.Node
+	href string
+}
+
+func (twc *tableWordCollector) collect(n *html.Node, style tableStyle) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			twc.addText(c.Data, style)
+		case html.ElementNode:
+			s := style
+			switch c.Data {
+			case "br":
+				twc.words = append(twc.words, tableWord{lineBreak: true})
+				twc.pendingSpace = false
+				continue
+			case "strong", "b", "th":
+				s.attr |= egg.AttrBold
+			case "em", "i":
+				s.attr |= egg.AttrUnderline
+			case "code":
+				s.fg = egg.ColorWhite
+				s.bg = egg.ColorBlack
+			case "a":
+				s.fg = egg.ColorBlue
+				if href, err := getAttribute(c, "href"); err == nil && !isFootnoteRef(c) && twc.link == nil {
+					twc.link, twc.href = c, href
+					twc.collect(c, s)
+					twc.link, twc.href = nil, ""
+					continue
+				}
+			}
+			twc.collect(c, s)
+		}
+	}
+}
+
+func (twc *tableWordCollector) addText(txt string, style tableStyle) {
+	normal := normaliseText(txt)
+	if strings.HasPrefix(normal, " ") {
+		twc.pendingSpace = true
+	}
+	for _, w := range strings.Fields(normal) {
+		twc.words = append(twc.words, tableWord{text: w, style: style, space: twc.pendingSpace, link: twc.link, href: twc.href})
+		twc.pendingSpace = true
+	}
+	twc.pendingSpace = strings.HasSuffix(normal, " ") || (twc.pendingSpace && strings.TrimSpace(normal) == "")
+}
+
+func tableColumnCount(rows []tableRow) int {
+	n := 0
+	for _, row := range rows {
+		if len(row.cells) > n {
+			n = len(row.cells)
+		}
+	}
+	return n
+}
+
+// tableColumnWidths - each column is as wide as its widest cell on one line,
+// then the widest columns are narrowed until the table fits the available width
+func tableColumnWidths(rows []tableRow, ncols, available int) []int {
+	widths := make([]int, ncols)
+	for _, row := range rows {
+		for i, cell := range row.cells {
+			for _, line := range wrapTableCell(cell.words, 0) {
+				if w := tableLineWidth(line); w > widths[i] {
+					widths[i] = w
+				}
+			}
+		}
+	}
+	for i := range widths {
+		if widths[i] == 0 {
+			widths[i] = 1
+		}
+	}
+
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	for total > available {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 1 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// wrapTableCell - split the words into lines no wider than width, chopping words that are too long.
+// A width of 0 only breaks lines at explicit line breaks
+func wrapTableCell(words []tableWord, width int) [][]tableWord {
+	lines := make([][]tableWord, 0)
+	line := make([]tableWord, 0)
+	lineW := 0
+	for _, word := range words {
+		if word.lineBreak {
+			lines = append(lines, line)
+			line, lineW = make([]tableWord, 0), 0
+			continue
+		}
+		w := runewidth.StringWidth(word.text)
+		sep := 0
+		if len(line) > 0 && word.space {
+			sep = 1
+		}
+		if width == 0 || lineW+sep+w <= width {
+			line = append(line, word)
+			lineW += sep + w
+			continue
+		}
+
+		if len(line) > 0 {
+			lines = append(lines, line)
+			line, lineW = make([]tableWord, 0), 0
+		}
+		for runewidth.StringWidth(word.text) > width {
+			head, tail := splitAtWidth(word.text, width)
+			chunk := word
+			chunk.text = head
+			lines = append(lines, []tableWord{chunk})
+			word.text = tail
+		}
+		line = append(line, word)
+		lineW = runewidth.StringWidth(word.text)
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// split a string at the last rune that fits in the width, always taking at least one rune
+func splitAtWidth(s string, width int) (string, string) {
+	w := 0
+	for i, r := range s {
+		rw := runewidth.RuneWidth(r)
+		if w+rw > width && i > 0 {
+			return s[:i], s[i:]
+		}
+		w += rw
+	}
+	return s, ""
+}
+
+func tableLineWidth(line []tableWord) int {
+	w := 0
+	for i, word := range line {
+		if i > 0 && word.space {
+			w++
+		}
+		w += runewidth.StringWidth(word.text)
+	}
+	return w
+}
+
+// how far to indent a line within a column, according to the cell's align attribute
+func tableAlignOffset(align string, lineW, width int) int {
+	if lineW >= width {
+		return 0
+	}
+	switch align {
+	case "right":
+		return width - lineW
+	case "center":
+		return (width - lineW) / 2
+	}
+	return 0
+}
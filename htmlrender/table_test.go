@@ -0,0 +1,97 @@
+package htmlrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/egg"
+	"golang.org/x/net/html"
+)
+
+func parseTestTable(src string) []tableRow {
+	node, _ := html.Parse(strings.NewReader(src))
+	var table *html.Node
+	var find func(*html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "table" {
+			table = n
+		}
+		for c := n.FirstChild; c != nil && table == nil; c = c.NextSibling {
+			find(c)
+		}
+	}
+	find(node)
+	return parseTableRows(table, tableStyle{})
+}
+
+func cellLines(words []tableWord, width int) []string {
+	res := make([]string, 0)
+	for _, line := range wrapTableCell(words, width) {
+		var sb strings.Builder
+		for i, w := range line {
+			if i > 0 && w.space {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(w.text)
+		}
+		res = append(res, sb.String())
+	}
+	return res
+}
+
+func TestParseTableRows(t *testing.T) {
+	rows := parseTestTable(`<table><thead><tr><th align="left">Name</th><th align="right">Size</th></tr></thead>
+<tbody><tr><td>the <strong>big</strong>, one</td><td align="right">10</td></tr><tr><td>small</td></tr></tbody></table>`)
+
+	assert.Equal(t, 3, len(rows))
+	assert.True(t, rows[0].header)
+	assert.False(t, rows[1].header)
+	assert.Equal(t, "right", rows[0].cells[1].align)
+	assert.Equal(t, 2, tableColumnCount(rows))
+	assert.Equal(t, []string{"the big, one"}, cellLines(rows[1].cells[0].words, 0))
+}
+
+func TestTableColumnWidths(t *testing.T) {
+	rows := parseTestTable(`<table><tr><th>A</th><th>Description</th></tr><tr><td>short</td><td>a much longer description</td></tr></table>`)
+
+	assert.Equal(t, []int{5, 25}, tableColumnWidths(rows, 2, 80))
+	// the widest column gives way first
+	assert.Equal(t, []int{5, 15}, tableColumnWidths(rows, 2, 20))
+	assert.Equal(t, []int{1, 1}, tableColumnWidths(rows, 2, 0))
+}
+
+func TestWrapTableCell(t *testing.T) {
+	rows := parseTestTable(`<table><tr><td>a much longer description<br>next</td></tr><tr><td>abcdefghij</td></tr></table>`)
+
+	assert.Equal(t, []string{"a much", "longer", "descripti", "on", "next"}, cellLines(rows[0].cells[0].words, 9))
+	assert.Equal(t, []string{"abcd", "efgh", "ij"}, cellLines(rows[1].cells[0].words, 4))
+	assert.Equal(t, []string{""}, cellLines(nil, 4))
+}
+
+func TestTableAlignOffset(t *testing.T) {
+	assert.Equal(t, 0, tableAlignOffset("left", 3, 10))
+	assert.Equal(t, 7, tableAlignOffset("right", 3, 10))
+	assert.Equal(t, 3, tableAlignOffset("center", 3, 10))
+	assert.Equal(t, 0, tableAlignOffset("right", 12, 10))
+}
+
+func TestTableLinks(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader(`<table><tr><th>Site</th></tr><tr><td>see <a href="https://a.com">the docs</a></td></tr></table>`))
+	// an empty viewport, so nothing is drawn to the screen
+	canvas := egg.Canvas{Bounds: egg.MakeBounds(0, 0, 80, 0), ViewPort: &egg.Bounds{}}
+
+	result := Render([]*html.Node{node}, canvas, Options{})
+	assert.Equal(t, 1, len(result.Links))
+	assert.Equal(t, "https://a.com", result.Links[0].Href)
+	assert.Equal(t, "a", result.Links[0].Node.Data)
+	// after the border, padding and `see `
+	assert.Equal(t, 6, result.Links[0].X)
+	assert.Equal(t, 3, result.Links[0].Y)
+
+	result = Render([]*html.Node{node}, canvas, Options{NumberLinks: true})
+	assert.Equal(t, 1, result.Links[0].Number)
+	rows := parseTestTable(`<table><tr><td><a href="https://a.com">the docs</a> and <a href="https://a.com">again</a></td></tr></table>`)
+	numberTableLinks(rows, nil)
+	assert.Equal(t, []string{"the docs[1] and again[1]"}, cellLines(rows[0].cells[0].words, 0))
+}
@@ -123,3 +123,8 @@ func TestSliceForLineWhenNoBreak(t *testing.T) {
 	assert.Equal(t, "ngwordthatdoesntbreak is that a fact?", remainder)
 	assert.Equal(t, false, finished)
 }
+
+func TestStrikethroughString(t *testing.T) {
+	assert.Equal(t, "a̶b̶", strikethroughString("ab"))
+	assert.Equal(t, "", strikethroughString(""))
+}
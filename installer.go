@@ -8,7 +8,12 @@ import (
 )
 
 func install() {
-	installDir := filepath.Join(config.GetAppConfig().HomeDir, ".notebee")
+	ac, err := config.GetAppConfig()
+	if err != nil {
+		// MakeConfig reports the error
+		return
+	}
+	installDir := filepath.Join(ac.HomeDir, ".notebee")
 
 	if _, err := os.Stat(installDir); os.IsNotExist(err) {
 		os.Mkdir(installDir, os.ModePerm)
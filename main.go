@@ -28,7 +28,10 @@ func main() {
 	}
 	install()
 	log.Println("App started")
-	config := config.MakeConfig()
+	config, err := config.MakeConfig()
 	controller := controller.InitMainController(config)
+	if err != nil {
+		controller.ShowError(err)
+	}
 	defer controller.Start()
 }
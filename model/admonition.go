@@ -0,0 +1,51 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// AdmonitionKind - the kind of a GitHub-style admonition, `> [!NOTE]`
+type AdmonitionKind string
+
+const (
+	AdmonitionNote      AdmonitionKind = "note"
+	AdmonitionTip       AdmonitionKind = "tip"
+	AdmonitionImportant AdmonitionKind = "important"
+	AdmonitionWarning   AdmonitionKind = "warning"
+	AdmonitionCaution   AdmonitionKind = "caution"
+)
+
+// ContextAdmonition - the admonition kind of a quote element
+const ContextAdmonition string = "admonition"
+
+var admonitionMarkerRegex = regexp.MustCompile(`(?i)^\s*\[!(note|tip|important|warning|caution)\][ \t]*\n?`)
+
+// AdmonitionMarker - if the blockquote is an admonition, the text node starting with its `[!KIND]` marker and the kind
+func AdmonitionMarker(blockquote *html.Node) (*html.Node, AdmonitionKind, bool) {
+	if blockquote == nil || blockquote.Type != html.ElementNode || blockquote.Data != "blockquote" {
+		return nil, "", false
+	}
+	for c := blockquote.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode && strings.TrimSpace(c.Data) == "" {
+			continue
+		}
+		if c.Type != html.ElementNode || c.Data != "p" || c.FirstChild == nil || c.FirstChild.Type != html.TextNode {
+			return nil, "", false
+		}
+		text := c.FirstChild
+		m := admonitionMarkerRegex.FindStringSubmatch(text.Data)
+		if m == nil {
+			return nil, "", false
+		}
+		return text, AdmonitionKind(strings.ToLower(m[1])), true
+	}
+	return nil, "", false
+}
+
+// StripAdmonitionMarker - the text of an admonition without the `[!KIND]` marker
+func StripAdmonitionMarker(s string) string {
+	return admonitionMarkerRegex.ReplaceAllString(s, "")
+}
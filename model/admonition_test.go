@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/util"
+)
+
+func TestAdmonitionMarker(t *testing.T) {
+	body, _ := util.MarkdownToNode([]byte("> [!WARNING]\n> Back up first\n\nbreak\n\n> just a quote\n>\n> > nested\n"))
+	quotes := childElements(body)
+
+	marker, kind, ok := AdmonitionMarker(quotes[0])
+	assert.True(t, ok)
+	assert.Equal(t, AdmonitionWarning, kind)
+	assert.Equal(t, "Back up first", StripAdmonitionMarker(marker.Data))
+
+	_, _, ok = AdmonitionMarker(quotes[2])
+	assert.False(t, ok)
+}
+
+func TestParseQuoteElement(t *testing.T) {
+	body, _ := util.MarkdownToNode([]byte("> [!note]\n> Read this\n\nbreak\n\n> first\n>\n> > nested\n>\n> - item\n"))
+	quotes := childElements(body)
+
+	note := parseElement(quotes[0], false)
+	assert.Equal(t, ElementTypeQuote, note.Type)
+	assert.Equal(t, "note", note.Context[ContextAdmonition])
+
+	quote := parseElement(quotes[2], false)
+	assert.Equal(t, ElementTypeQuote, quote.Type)
+	assert.Nil(t, quote.Context)
+	assert.Equal(t, 3, len(quote.SubElements))
+	assert.Equal(t, ElementTypeQuote, quote.SubElements[1].Type)
+	assert.Equal(t, ElementTypeUnorderedList, quote.SubElements[2].Type)
+}
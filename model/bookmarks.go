@@ -0,0 +1,113 @@
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// BookmarkPrefix - starts a traversal to a bookmark, e.g. `> @deploy`
+const BookmarkPrefix = "@"
+
+// Bookmark - a named place to jump back to
+type Bookmark struct {
+	Name string
+	Place
+}
+
+// Bookmarks - the bookmarks in order of name, and the file they are saved in
+type Bookmarks struct {
+	Marks []Bookmark
+	file  string
+	// loadErr - why the file could not be loaded, in which case it isn't saved over
+	loadErr error
+}
+
+// LoadBookmarks - the bookmarks saved in the file, or no bookmarks, to be saved there.
+// If the file can't be read the bookmarks are empty, the error says why, and the file is left as it is
+func LoadBookmarks(file string) (*Bookmarks, error) {
+	b := Bookmarks{Marks: make([]Bookmark, 0), file: file}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return &b, nil
+	} else if err != nil {
+		b.loadErr = util.FileError(err, "could not read %s", file)
+	} else if err := json.Unmarshal(data, &b.Marks); err != nil {
+		b.Marks = make([]Bookmark, 0)
+		b.loadErr = util.FileError(err, "%s is not valid JSON", file)
+	}
+	return &b, b.loadErr
+}
+
+// Get - the bookmark with the name
+func (b *Bookmarks) Get(name string) (Bookmark, bool) {
+	for _, m := range b.Marks {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Bookmark{}, false
+}
+
+// Set - add the bookmark, replacing any with the same name. Returns the error if the bookmarks could not be saved
+func (b *Bookmarks) Set(mark Bookmark) error {
+	b.remove(mark.Name)
+	b.Marks = append(b.Marks, mark)
+	sort.SliceStable(b.Marks, func(i, j int) bool { return b.Marks[i].Name < b.Marks[j].Name })
+	return b.save()
+}
+
+// Remove - remove the bookmark with the name. Returns false if there is none,
+// and the error if the bookmarks could not be saved
+func (b *Bookmarks) Remove(name string) (bool, error) {
+	if !b.remove(name) {
+		return false, nil
+	}
+	return true, b.save()
+}
+
+func (b *Bookmarks) remove(name string) bool {
+	for i, m := range b.Marks {
+		if m.Name == name {
+			b.Marks = append(b.Marks[:i], b.Marks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Complete - the names of the bookmarks starting with what has been typed, ignoring case
+func (b *Bookmarks) Complete(typed string) []AutocompleteResult {
+	res := make([]AutocompleteResult, 0)
+	for _, m := range b.Marks {
+		if strings.HasPrefix(strings.ToLower(m.Name), strings.ToLower(typed)) {
+			res = append(res, AutocompleteResult{
+				Str:     m.Name,
+				Kind:    AutocompleteKindBookmark,
+				Matches: PrefixMatches(typed),
+			})
+		}
+	}
+	return res
+}
+
+func (b *Bookmarks) save() error {
+	if b.file == "" {
+		return nil
+	}
+	if b.loadErr != nil {
+		return util.FileError(b.loadErr, "not saving over %s", b.file)
+	}
+	data, err := json.Marshal(b.Marks)
+	if err == nil {
+		err = ioutil.WriteFile(b.file, data, 0644)
+	}
+	if err != nil {
+		return util.FileError(err, "could not save the bookmarks to %s", b.file)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/util"
+)
+
+func TestBookmarks(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "bookmarks")
+
+	b, err := LoadBookmarks(file)
+	assert.Nil(t, err)
+	assert.Nil(t, b.Set(Bookmark{Name: "pods", Place: Place{Path: "/notes/k8s/pods.md", HeadingPath: []string{"Probes"}, Scroll: 4}}))
+	b.Set(Bookmark{Name: "deploy", Place: Place{Path: "/notes/release.md"}})
+	b.Set(Bookmark{Name: "Docker", Place: Place{Path: "/notes/docker.md"}})
+	// replaces the bookmark with the same name
+	b.Set(Bookmark{Name: "deploy", Place: Place{Path: "/notes/deploy.md"}})
+
+	loaded, _ := LoadBookmarks(file)
+	assert.Equal(t, 3, len(loaded.Marks))
+	mark, ok := loaded.Get("pods")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Probes"}, mark.HeadingPath)
+	assert.Equal(t, 4, mark.Scroll)
+	mark, _ = loaded.Get("deploy")
+	assert.Equal(t, "/notes/deploy.md", mark.Path)
+
+	names := func(typed string) []string {
+		res := make([]string, 0)
+		for _, r := range loaded.Complete(typed) {
+			assert.Equal(t, AutocompleteKindBookmark, r.Kind)
+			res = append(res, r.Str)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"Docker", "deploy"}, names("d"))
+	assert.Equal(t, []string{"pods"}, names("P"))
+
+	removed, err := loaded.Remove("pods")
+	assert.True(t, removed)
+	assert.Nil(t, err)
+	removed, _ = loaded.Remove("pods")
+	assert.False(t, removed)
+	reloaded, _ := LoadBookmarks(file)
+	_, ok = reloaded.Get("pods")
+	assert.False(t, ok)
+}
+
+func TestBookmarksSaveError(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	b, _ := LoadBookmarks(filepath.Join(dir, "missing", "bookmarks"))
+
+	err := b.Set(Bookmark{Name: "pods", Place: Place{Path: "/notes/k8s/pods.md"}})
+	assert.True(t, util.IsErrorKind(err, util.ErrorFile))
+}
+
+func TestBookmarksCorruptFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "bookmarks")
+	ioutil.WriteFile(file, []byte("not json"), 0644)
+
+	b, err := LoadBookmarks(file)
+	assert.True(t, util.IsErrorKind(err, util.ErrorFile))
+	assert.Equal(t, 0, len(b.Marks))
+	// the file isn't saved over
+	assert.NotNil(t, b.Set(Bookmark{Name: "pods", Place: Place{Path: "/notes/k8s/pods.md"}}))
+	data, _ := ioutil.ReadFile(file)
+	assert.Equal(t, "not json", string(data))
+}
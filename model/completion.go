@@ -1,15 +1,192 @@
 package model
 
-import "os"
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// AutocompleteKind - what a completion refers to
+type AutocompleteKind uint8
+
+const (
+	// AutocompleteKindFile - a note
+	AutocompleteKindFile AutocompleteKind = iota
+	// AutocompleteKindDirectory - a directory of notes
+	AutocompleteKindDirectory
+	// AutocompleteKindHeading - a heading within a note
+	AutocompleteKindHeading
+	// AutocompleteKindCommand - the name of a command
+	AutocompleteKindCommand
+	// AutocompleteKindArgument - any other argument to a command
+	AutocompleteKindArgument
+	// AutocompleteKindBookmark - the name of a bookmark
+	AutocompleteKindBookmark
+)
 
 type AutocompleteResult struct {
-	Str   string
-	IsDir bool
+	Str  string
+	Kind AutocompleteKind
+	// Heading - for heading completions, the heading words at the end of Str
+	Heading string
+	// Score - how well the completion matched, for fuzzy completions
+	Score int
+	// Matches - the indices of the runes in Str matching the query
+	Matches []int
+}
+
+// IsDir - is the completion a directory
+func (ar *AutocompleteResult) IsDir() bool {
+	return ar.Kind == AutocompleteKindDirectory
 }
 
 func (ar *AutocompleteResult) CompletionStr() string {
-	if ar.IsDir {
+	switch ar.Kind {
+	case AutocompleteKindDirectory:
 		return ar.Str + string(os.PathSeparator)
+	case AutocompleteKindCommand:
+		// ready for the arguments
+		return ar.Str + " "
 	}
 	return ar.Str
 }
+
+// WithPrefix - a copy of the result with the prefix prepended to Str, keeping the matches in place
+func (ar AutocompleteResult) WithPrefix(prefix string) AutocompleteResult {
+	n := utf8.RuneCountInString(prefix)
+	matches := make([]int, len(ar.Matches))
+	for i, m := range ar.Matches {
+		matches[i] = m + n
+	}
+	ar.Str = prefix + ar.Str
+	ar.Matches = matches
+	return ar
+}
+
+// PrefixMatches - the rune indices matched by a completion starting with the prefix
+func PrefixMatches(prefix string) []int {
+	res := make([]int, 0)
+	for i := range []rune(prefix) {
+		res = append(res, i)
+	}
+	return res
+}
+
+// CompleteDirectories - complete a partially typed filesystem path to the directories it could be.
+// Hidden directories are only offered once a `.` has been typed, and a leading `~` is the home directory
+func CompleteDirectories(typed string) []AutocompleteResult {
+	res := make([]AutocompleteResult, 0)
+	dir, base := filepath.Split(typed)
+
+	readDir, err := util.ExpandHome(dir)
+	if err != nil {
+		return res
+	}
+	if readDir == "" {
+		readDir = "."
+	}
+	infos, err := ioutil.ReadDir(readDir)
+	if err != nil {
+		return res
+	}
+
+	matches := make([]int, 0)
+	for i := range []rune(typed) {
+		matches = append(matches, i)
+	}
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		// follow symlinks to directories
+		if stat, err := os.Stat(filepath.Join(readDir, name)); err != nil || !stat.IsDir() {
+			continue
+		}
+		res = append(res, AutocompleteResult{
+			Str:     dir + name,
+			Kind:    AutocompleteKindDirectory,
+			Matches: matches,
+		})
+	}
+	return res
+}
+
+// PrefixCompletePaths - the next part of the query paths of files starting with the fragment:
+// the note's name, or the directory it is in below the fragment's directory. An exact match comes first,
+// then the shortest, then in alphabetical order
+func PrefixCompletePaths(fragment string, files []FilePath) []AutocompleteResult {
+	res := make([]AutocompleteResult, 0)
+	topCompleteDir := filepath.Dir(fragment)
+	seen := make(map[string]bool)
+	for _, f := range files {
+		qp := f.QueryPath()
+		if !strings.HasPrefix(qp, fragment) {
+			continue
+		}
+		relativeToQ, _ := filepath.Rel(topCompleteDir, qp)
+		remainingInPath := strings.Split(relativeToQ, string(os.PathSeparator))
+		fullCompletion := filepath.Join(topCompleteDir, remainingInPath[0])
+		if seen[fullCompletion] {
+			continue
+		}
+		seen[fullCompletion] = true
+
+		compl := AutocompleteResult{Str: fullCompletion}
+		if len(remainingInPath) > 1 {
+			compl.Kind = AutocompleteKindDirectory
+		}
+		if strings.HasPrefix(fullCompletion, fragment) {
+			compl.Matches = PrefixMatches(fragment)
+		}
+		res = append(res, compl)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if exactI, exactJ := res[i].Str == fragment, res[j].Str == fragment; exactI != exactJ {
+			return exactI
+		}
+		if len(res[i].Str) != len(res[j].Str) {
+			return len(res[i].Str) < len(res[j].Str)
+		}
+		return res[i].Str < res[j].Str
+	})
+	return res
+}
+
+// FuzzyCompletePaths - the query paths of files matching the fragment as a subsequence (see util.FuzzyMatch),
+// best match first
+func FuzzyCompletePaths(fragment string, files []FilePath) []AutocompleteResult {
+	res := make([]AutocompleteResult, 0)
+	seen := make(map[string]bool)
+	for _, f := range files {
+		qp := f.QueryPath()
+		if seen[qp] {
+			continue
+		}
+		seen[qp] = true
+		if score, matches, ok := util.FuzzyMatch(fragment, qp); ok {
+			res = append(res, AutocompleteResult{
+				Str:     qp,
+				Score:   score,
+				Matches: matches,
+			})
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		if len(res[i].Str) != len(res[j].Str) {
+			return len(res[i].Str) < len(res[j].Str)
+		}
+		return res[i].Str < res[j].Str
+	})
+	return res
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/util"
+)
+
+func TestFuzzyCompletePaths(t *testing.T) {
+	files := []FilePath{
+		{Relative: "rust/ownership-lifetimes.md"},
+		{Relative: "kubernetes/rollouts.md"},
+		{Relative: "kubernetes/rollouts.md"},
+		{Relative: "git/rebase.md"},
+	}
+
+	res := FuzzyCompletePaths("kubro", files)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, "kubernetes/rollouts", res[0].Str)
+	assert.Equal(t, []int{0, 1, 2, 11, 12}, res[0].Matches)
+
+	res = FuzzyCompletePaths("rol", files)
+	assert.Equal(t, 2, len(res))
+	assert.Equal(t, "kubernetes/rollouts", res[0].Str)
+	assert.Equal(t, "rust/ownership-lifetimes", res[1].Str)
+}
+
+func TestPrefixCompletePaths(t *testing.T) {
+	files := []FilePath{
+		{Relative: "kubernetes/rollouts.md"},
+		{Relative: "kube-proxy.md"},
+		{Relative: "kubernetes.md"},
+		{Relative: "kube.md"},
+		{Relative: "kubectl.md"},
+		{Relative: "git/rebase.md"},
+	}
+	strs := func(res []AutocompleteResult) []string {
+		ss := make([]string, 0)
+		for _, r := range res {
+			ss = append(ss, r.Str)
+		}
+		return ss
+	}
+
+	res := PrefixCompletePaths("kube", files)
+	// exact, then shortest, then alphabetical
+	assert.Equal(t, []string{"kube", "kubectl", "kube-proxy", "kubernetes"}, strs(res))
+	assert.True(t, res[3].IsDir())
+	assert.Equal(t, []int{0, 1, 2, 3}, res[0].Matches)
+
+	res = PrefixCompletePaths("kubernetes/", files)
+	assert.Equal(t, []string{"kubernetes/rollouts"}, strs(res))
+	assert.Equal(t, []string{"git", "kube", "kubectl", "kube-proxy", "kubernetes"}, strs(PrefixCompletePaths("", files)))
+}
+
+func TestAutocompleteResultWithPrefix(t *testing.T) {
+	res := AutocompleteResult{Str: "rebase", Matches: []int{0, 1}}.WithPrefix("* wiki:")
+
+	assert.Equal(t, "* wiki:rebase", res.Str)
+	assert.Equal(t, []int{7, 8}, res.Matches)
+}
+
+func TestCompleteDirectories(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "notes", "work"), 0755)
+	os.MkdirAll(filepath.Join(dir, "notebooks"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".hidden"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes"), 0644)
+
+	strs := func(typed string) []string {
+		res := make([]string, 0)
+		for _, r := range CompleteDirectories(typed) {
+			assert.Equal(t, AutocompleteKindDirectory, r.Kind)
+			res = append(res, r.Str)
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{dir + "/notebooks", dir + "/notes"}, strs(dir+"/no"))
+	assert.Equal(t, []string{dir + "/notes/work"}, strs(dir+"/notes/"))
+	assert.Equal(t, []string{dir + "/notebooks", dir + "/notes"}, strs(dir+"/"))
+	assert.Equal(t, []string{dir + "/.hidden"}, strs(dir+"/."))
+	assert.Equal(t, []string{}, strs(dir+"/missing/"))
+}
+
+func TestCompleteHomeDirectoryResolves(t *testing.T) {
+	home, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(home)
+	os.MkdirAll(filepath.Join(home, "xnotes"), 0755)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	res := CompleteDirectories("~/x")
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, "~/xnotes", res[0].Str)
+
+	// what `: +` and `: cd` do with the completion
+	path, err := util.ResolveDirectory(res[0].Str)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "xnotes"), path)
+
+	_, err = util.ResolveDirectory("~/missing")
+	assert.Equal(t, "~/missing does not exist", err.Error())
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/thomgray/notebee/config"
@@ -15,11 +16,14 @@ type Location struct {
 	BaseDir              string
 	RelativePath         []string
 	RelativePathWithName string
+	File                 *File
+	Document             *Document
 }
 
 type FilePath struct {
 	Full     string
 	BaseDir  string
+	Alias    string
 	Relative string
 	FileInfo os.FileInfo
 }
@@ -29,6 +33,37 @@ func (fp FilePath) QueryPath() string {
 	return strings.TrimSuffix(fp.Relative, ext)
 }
 
+// QualifiedQueryPath - the query path prefixed with the alias of its root, e.g. wiki:git/rebase.
+// This is unambiguous even when several roots contain the same query path
+func (fp FilePath) QualifiedQueryPath() string {
+	return fp.Alias + RootAliasSeparator + fp.QueryPath()
+}
+
+// RootAliasSeparator - separates a root alias from the query path
+const RootAliasSeparator = ":"
+
+// SearchRoot - a directory notes are loaded from, and the alias used to refer to it
+type SearchRoot struct {
+	Path  string
+	Alias string
+}
+
+// SplitRootAlias - split an "alias:" prefix naming one of the roots from a query path.
+// The alias is empty if there is none, and the query is a literal path
+func SplitRootAlias(query string, roots []SearchRoot) (string, string) {
+	i := strings.Index(query, RootAliasSeparator)
+	if i < 0 {
+		return "", query
+	}
+	alias := query[:i]
+	for _, root := range roots {
+		if strings.EqualFold(root.Alias, alias) {
+			return alias, query[i+len(RootAliasSeparator):]
+		}
+	}
+	return "", query
+}
+
 type File struct {
 	Path      string
 	Extension string
@@ -43,21 +78,43 @@ type FileManager struct {
 	Files           []*File
 	Config          *config.Config
 	CurrentLocation *Location
+	Index           *Index
 }
 
 func MakeFileManager(config *config.Config) *FileManager {
 	fm := FileManager{
 		Config: config,
+		Index:  MakeIndex(),
 	}
 
 	return &fm
 }
 
+// Reload - walk the search roots and refresh the index
+func (fm *FileManager) Reload() IndexChanges {
+	return fm.Index.Refresh(fm.walkAllSupportedFilePaths())
+}
+
+// IndexEntries - every indexed note, loading the index first if needed
+func (fm *FileManager) IndexEntries() []*IndexEntry {
+	if !fm.Index.IsLoaded() {
+		fm.Reload()
+	}
+	return fm.Index.Entries()
+}
+
+// LoadFile - the parsed file for a path, from the index if it is there
+func (fm *FileManager) LoadFile(p FilePath) (*File, error) {
+	if e := fm.Index.Get(p.Full); e != nil {
+		return e.File, e.Err
+	}
+	return LoadCodeFile(p.Full)
+}
+
 func (fm *FileManager) LoadFiles(filepaths []string) {
 	files := make([]*File, 0)
 	for _, path := range filepaths {
-		f := LoadCodeFile(path)
-		if f != nil {
+		if f, err := LoadCodeFile(path); err == nil {
 			files = append(files, f)
 		}
 	}
@@ -129,20 +186,105 @@ func completionString(info os.FileInfo, base string) string {
 	)
 }
 
+// Roots - the configured search roots with their aliases.
+// An alias is the base name of the directory, or if several roots have the same base name, the name of
+// its parent and the base name, e.g. work-notes. So an alias doesn't change when the order of the roots does
+func (fm *FileManager) Roots() []SearchRoot {
+	return makeSearchRoots(fm.Config.Roots())
+}
+
+func makeSearchRoots(paths []string) []SearchRoot {
+	bases := make(map[string]int)
+	for _, p := range paths {
+		bases[strings.ToLower(filepath.Base(p))]++
+	}
+
+	res := make([]SearchRoot, 0)
+	taken := make([]string, 0)
+	for _, p := range paths {
+		base := strings.ToLower(filepath.Base(p))
+		if bases[base] > 1 {
+			base = strings.ToLower(filepath.Base(filepath.Dir(p))) + "-" + base
+		}
+		alias := base
+		for i := 2; util.StringSliceContains(taken, alias); i++ {
+			alias = base + strconv.Itoa(i)
+		}
+		taken = append(taken, alias)
+		res = append(res, SearchRoot{Path: p, Alias: alias})
+	}
+	return res
+}
+
+// FindSupportedFilePaths - the merged namespace of notes across all roots.
+// Where several roots contain the same query path, the one in the root of highest precedence wins
+// (see config.Roots); the others are only reachable by their qualified query path
 func (fm *FileManager) FindSupportedFilePaths() []FilePath {
+	return mergeFilePaths(fm.FindAllSupportedFilePaths())
+}
+
+func mergeFilePaths(all []FilePath) []FilePath {
+	res := make([]FilePath, 0)
+	seen := make(map[string]bool)
+	for _, fp := range all {
+		key := strings.ToLower(fp.QueryPath())
+		if !seen[key] {
+			seen[key] = true
+			res = append(res, fp)
+		}
+	}
+	return res
+}
+
+// FindAllSupportedFilePaths - every note in every root, including those shadowed in the merged namespace
+func (fm *FileManager) FindAllSupportedFilePaths() []FilePath {
+	if !fm.Index.IsLoaded() {
+		fm.Reload()
+	}
+	return fm.Index.Paths()
+}
+
+func (fm *FileManager) walkAllSupportedFilePaths() []FilePath {
 	res := make([]FilePath, 0)
+	for _, root := range fm.Roots() {
+		res = append(res, findSupportedFilePathsInRoot(root)...)
+	}
+	return res
+}
 
-	docRoot := fm.Config.DocumentRoot()
-	if docRoot == nil {
-		return res
+// FindSupportedFilePathsForQuery - the notes a query path may refer to, and the query path without any alias.
+// An aliased query (e.g. wiki:git/rebase) only considers notes in that root
+func (fm *FileManager) FindSupportedFilePathsForQuery(query string) ([]FilePath, string) {
+	alias, path := SplitRootAlias(query, fm.Roots())
+	if alias == "" {
+		return fm.FindSupportedFilePaths(), path
 	}
+	res := make([]FilePath, 0)
+	for _, fp := range fm.FindAllSupportedFilePaths() {
+		if strings.EqualFold(fp.Alias, alias) {
+			res = append(res, fp)
+		}
+	}
+	return res, path
+}
 
-	filepath.Walk(*docRoot, func(path string, info os.FileInfo, err error) error {
+func findSupportedFilePathsInRoot(root SearchRoot) []FilePath {
+	res := make([]FilePath, 0)
+
+	filepath.Walk(root.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info == nil {
+			return nil
+		}
+		if info.IsDir() && path != root.Path && strings.HasPrefix(info.Name(), ".") {
+			// skip hidden directories, e.g. .git
+			return filepath.SkipDir
+		}
 		if isSupportedFile(info) {
-			if relative, err := filepath.Rel(*docRoot, path); err == nil {
+			if relative, err := filepath.Rel(root.Path, path); err == nil {
 				fp := FilePath{
 					Full:     path,
-					BaseDir:  *docRoot,
+					BaseDir:  root.Path,
+					Alias:    root.Alias,
 					Relative: relative,
 					FileInfo: info,
 				}
@@ -196,8 +338,7 @@ func (fm *FileManager) TraversePath(path string) []*File {
 				log.Println(fileWithoutExt)
 				if strings.EqualFold(fileName, fileWithoutExt) {
 					fullFilePath := filepath.Join(fullDirPath, fileInDirName)
-					file := LoadCodeFile(fullFilePath)
-					if file != nil {
+					if file, err := LoadCodeFile(fullFilePath); err == nil {
 						files = append(files, file)
 						log.Println("Matched a file!")
 					}
@@ -209,7 +350,8 @@ func (fm *FileManager) TraversePath(path string) []*File {
 	return files
 }
 
-func LoadCodeFile(path string) *File {
+// LoadCodeFile - read and parse a note. If it can't be read, the file is empty and the error says why
+func LoadCodeFile(path string) (*File, error) {
 	extn := filepath.Ext(path)
 	_, n := filepath.Split(path)
 	filename := strings.TrimSuffix(n, extn)
@@ -219,23 +361,27 @@ func LoadCodeFile(path string) *File {
 		Extension: extn,
 		Name:      filename,
 	}
-	fc, _ := util.ReadFile(path)
+	fc, err := util.ReadFile(path)
 	file.Content = fc
+	if os.IsNotExist(err) {
+		return &file, util.NotFound("%s does not exist", path)
+	} else if err != nil {
+		return &file, util.FileError(err, "could not read %s", path)
+	}
 
-	if extn == ".md" {
-		node, err := util.MarkdownToNode(fc)
-		if err == nil {
-			// md := DocumentFromNode(node, filename)
-			file.Body = node
-			// file.Document = md
-		}
-	} else if extn == ".html" {
-		node, err := util.HtmlToNode(fc)
-		if err == nil {
-			// md := DocumentFromNode(node, filename)
-			// file.Document = md
-			file.Body = node
-		}
+	var node *html.Node
+	switch extn {
+	case ".md":
+		node, err = util.MarkdownToNode(fc)
+	case ".html":
+		node, err = util.HtmlToNode(fc)
+	default:
+		return &file, nil
+	}
+	if err != nil {
+		return &file, util.FileError(err, "could not parse %s", path)
 	}
-	return &file
+	file.Body = node
+	file.Document = DocumentFromNode(node, filename)
+	return &file, nil
 }
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+	"github.com/thomgray/notebee/util"
+)
+
+func writeNote(t *testing.T, root, relative, content string) {
+	full := filepath.Join(root, relative)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(full), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(full, []byte(content), 0644))
+}
+
+func TestSplitRootAlias(t *testing.T) {
+	roots := []SearchRoot{{Path: "/b/Wiki", Alias: "wiki"}}
+	alias, path := SplitRootAlias("Wiki:git/rebase", roots)
+	assert.Equal(t, "Wiki", alias)
+	assert.Equal(t, "git/rebase", path)
+
+	alias, path = SplitRootAlias("git/rebase", roots)
+	assert.Equal(t, "", alias)
+	assert.Equal(t, "git/rebase", path)
+
+	// not a root, so a literal path
+	alias, path = SplitRootAlias("meeting 10:30", roots)
+	assert.Equal(t, "", alias)
+	assert.Equal(t, "meeting 10:30", path)
+}
+
+func TestMakeSearchRoots(t *testing.T) {
+	roots := makeSearchRoots([]string{"/a/notes", "/b/Wiki", "/c/notes", "/d/a/notes"})
+	assert.Equal(t, []SearchRoot{
+		{Path: "/a/notes", Alias: "a-notes"},
+		{Path: "/b/Wiki", Alias: "wiki"},
+		{Path: "/c/notes", Alias: "c-notes"},
+		{Path: "/d/a/notes", Alias: "a-notes2"},
+	}, roots)
+	// the aliases don't depend on the order of the roots
+	assert.Equal(t, "c-notes", makeSearchRoots([]string{"/c/notes", "/a/notes"})[0].Alias)
+}
+
+func TestFindSupportedFilePathsAcrossRoots(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+
+	wiki := filepath.Join(dir, "wiki")
+	personal := filepath.Join(dir, "personal")
+	writeNote(t, wiki, "git/rebase.md", "# wiki rebase")
+	writeNote(t, wiki, ".git/ignored.md", "# ignored")
+	writeNote(t, personal, "git/rebase.md", "# personal rebase")
+	writeNote(t, personal, "todo.md", "# todo")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{wiki, personal}})
+
+	all := fm.FindAllSupportedFilePaths()
+	assert.Equal(t, 3, len(all))
+
+	merged := fm.FindSupportedFilePaths()
+	assert.Equal(t, 2, len(merged))
+	assert.Equal(t, "wiki", merged[0].Alias)
+	assert.Equal(t, "git/rebase", merged[0].QueryPath())
+	assert.Equal(t, "personal:todo", merged[1].QualifiedQueryPath())
+
+	inPersonal, path := fm.FindSupportedFilePathsForQuery("personal:git/rebase")
+	assert.Equal(t, "git/rebase", path)
+	assert.Equal(t, 2, len(inPersonal))
+	assert.Equal(t, "personal", inPersonal[0].Alias)
+
+	loc, err := fm.Traverse("* personal:git/rebase")
+	assert.NoError(t, err)
+	assert.NotNil(t, loc)
+	assert.Equal(t, "personal rebase", loc.Document.SearchTerm)
+
+	loc, _ = fm.Traverse("* git/rebase")
+	assert.Equal(t, "wiki rebase", loc.Document.SearchTerm)
+
+	_, err = fm.Traverse("* git/merge")
+	assert.Equal(t, "no note matches 'git/merge'", err.Error())
+	_, err = fm.Traverse("* git/rebase missing")
+	assert.Equal(t, "no heading in git/rebase matches 'missing'", err.Error())
+}
+
+func TestLoadCodeFileReportsMissingFile(t *testing.T) {
+	f, err := LoadCodeFile("/no/such/note.md")
+	assert.NotNil(t, f)
+	assert.Nil(t, f.Document)
+	assert.True(t, util.IsErrorKind(err, util.ErrorNotFound))
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// FindHighlighter - highlights every occurrence of the text in a note. Matching ignores case unless the text
+// has an upper case letter, and `/pattern/flags` is a regular expression as in search
+func FindHighlighter(text string) (Highlighter, error) {
+	if text == "" {
+		return nil, util.Invalid("nothing to find")
+	}
+	if _, _, ok := splitRegexQuery(text); ok {
+		m, err := parseRegexSearch(text)
+		if err != nil {
+			return nil, err
+		}
+		return m.highlighter(), nil
+	}
+	pattern := regexp.QuoteMeta(text)
+	if strings.ToLower(text) == text {
+		pattern = "(?i)" + pattern
+	}
+	return regexSearch{regexp.MustCompile(pattern)}.highlighter(), nil
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindHighlighter(t *testing.T) {
+	h, err := FindHighlighter("pod")
+	assert.Nil(t, err)
+	assert.Equal(t, []TextRange{{0, 3}, {13, 16}}, h("Pods contain pods (a.b)"))
+
+	h, _ = FindHighlighter("Pod")
+	assert.Equal(t, []TextRange{{0, 3}}, h("Pods contain pods"))
+
+	// literal, not a pattern
+	h, _ = FindHighlighter("a.b")
+	assert.Equal(t, []TextRange{{1, 4}}, h("(a.b) axb"))
+
+	h, _ = FindHighlighter("/a.b/")
+	assert.Equal(t, []TextRange{{1, 4}, {6, 9}}, h("(a.b) axb"))
+	// escapes don't make a pattern case sensitive
+	h, _ = FindHighlighter(`/pod\S*/`)
+	assert.Equal(t, []TextRange{{0, 4}}, h("PODS"))
+
+	// a path is found literally
+	h, _ = FindHighlighter("/etc/hosts")
+	assert.Equal(t, []TextRange{{4, 14}}, h("see /etc/hosts"))
+
+	_, err = FindHighlighter("/(/")
+	assert.NotNil(t, err)
+	_, err = FindHighlighter("")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// HistoryEntry - a place visited, and when
+type HistoryEntry struct {
+	Place
+	Visited time.Time
+}
+
+// History - the places visited, oldest first, and the position moving back and forward through them.
+// The position is past the last entry when nothing from the history is displayed, e.g. in a new session
+type History struct {
+	Entries  []HistoryEntry
+	Position int
+	file     string
+	// loadErr - why the file could not be loaded, in which case it isn't saved over
+	loadErr error
+}
+
+// MaxHistory - the number of entries kept, dropping the oldest first
+const MaxHistory = 200
+
+// LoadHistory - the history saved in the file, or an empty history that will be saved there.
+// If the file can't be read the history is empty, the error says why, and the file is left as it is
+func LoadHistory(file string) (*History, error) {
+	h := History{Entries: make([]HistoryEntry, 0), file: file}
+	data, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		h.loadErr = util.FileError(err, "could not read %s", file)
+	} else if err == nil {
+		if err := json.Unmarshal(data, &h.Entries); err != nil {
+			h.Entries = make([]HistoryEntry, 0)
+			h.loadErr = util.FileError(err, "%s is not valid JSON", file)
+		}
+	}
+	h.Position = len(h.Entries)
+	return &h, h.loadErr
+}
+
+// Current - the entry being displayed, or nil
+func (h *History) Current() *HistoryEntry {
+	if h.Position < 0 || h.Position >= len(h.Entries) {
+		return nil
+	}
+	return &h.Entries[h.Position]
+}
+
+// Visit - add a place after the current position, dropping any entries forward of it.
+// Revisiting the current place only updates it. Returns the error if the history could not be saved
+func (h *History) Visit(e HistoryEntry) error {
+	if cur := h.Current(); cur != nil && cur.Place.same(e.Place) {
+		cur.Visited = e.Visited
+		return h.save()
+	}
+	if h.Position < len(h.Entries) {
+		h.Entries = h.Entries[:h.Position+1]
+	}
+	h.Entries = append(h.Entries, e)
+	if len(h.Entries) > MaxHistory {
+		h.Entries = h.Entries[len(h.Entries)-MaxHistory:]
+	}
+	h.Position = len(h.Entries) - 1
+	return h.save()
+}
+
+// UpdateScroll - record how far the current entry is scrolled, before moving away from it
+func (h *History) UpdateScroll(scroll int) {
+	if cur := h.Current(); cur != nil {
+		cur.Scroll = scroll
+	}
+}
+
+// Back - move to the previous entry, if there is one. The error is set if the history could not be saved
+func (h *History) Back() (HistoryEntry, bool, error) {
+	if h.Position <= 0 || len(h.Entries) == 0 {
+		return HistoryEntry{}, false, nil
+	}
+	h.Position--
+	h.Entries[h.Position].Visited = time.Now()
+	return h.Entries[h.Position], true, h.save()
+}
+
+// Forward - move to the next entry, if there is one. The error is set if the history could not be saved
+func (h *History) Forward() (HistoryEntry, bool, error) {
+	if h.Position >= len(h.Entries)-1 {
+		return HistoryEntry{}, false, nil
+	}
+	h.Position++
+	h.Entries[h.Position].Visited = time.Now()
+	return h.Entries[h.Position], true, h.save()
+}
+
+// Recent - the notes visited, most recent first, each with the last place visited in it
+func (h *History) Recent(limit int) []HistoryEntry {
+	byVisit := make([]HistoryEntry, len(h.Entries))
+	for i, e := range h.Entries {
+		byVisit[len(h.Entries)-1-i] = e
+	}
+	sort.SliceStable(byVisit, func(i, j int) bool { return byVisit[i].Visited.After(byVisit[j].Visited) })
+
+	res := make([]HistoryEntry, 0)
+	seen := make(map[string]bool)
+	for _, e := range byVisit {
+		if len(res) >= limit {
+			break
+		}
+		if !seen[e.Path] {
+			seen[e.Path] = true
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func (h *History) save() error {
+	if h.file == "" {
+		return nil
+	}
+	if h.loadErr != nil {
+		return util.FileError(h.loadErr, "not saving over %s", h.file)
+	}
+	data, err := json.Marshal(h.Entries)
+	if err == nil {
+		err = ioutil.WriteFile(h.file, data, 0644)
+	}
+	if err != nil {
+		return util.FileError(err, "could not save the history to %s", h.file)
+	}
+	return nil
+}
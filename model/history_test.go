@@ -0,0 +1,120 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/util"
+	"golang.org/x/net/html"
+)
+
+func TestHistoryBackAndForward(t *testing.T) {
+	h, _ := LoadHistory("")
+	_, ok, _ := h.Back()
+	assert.False(t, ok)
+
+	h.Visit(HistoryEntry{Place: Place{Path: "/notes/a.md"}})
+	h.Visit(HistoryEntry{Place: Place{Path: "/notes/b.md"}})
+	h.UpdateScroll(12)
+	h.Visit(HistoryEntry{Place: Place{Path: "/notes/c.md"}})
+
+	e, ok, _ := h.Back()
+	assert.True(t, ok)
+	assert.Equal(t, "/notes/b.md", e.Path)
+	assert.Equal(t, 12, e.Scroll)
+
+	e, ok, _ = h.Forward()
+	assert.True(t, ok)
+	assert.Equal(t, "/notes/c.md", e.Path)
+	_, ok, _ = h.Forward()
+	assert.False(t, ok)
+
+	// visiting after going back drops the entries forward of it
+	h.Back()
+	h.Back()
+	h.Visit(HistoryEntry{Place: Place{Path: "/notes/d.md"}})
+	assert.Equal(t, 2, len(h.Entries))
+	_, ok, _ = h.Forward()
+	assert.False(t, ok)
+
+	// revisiting the current place doesn't add an entry
+	h.Visit(HistoryEntry{Place: Place{Path: "/notes/d.md"}})
+	assert.Equal(t, 2, len(h.Entries))
+}
+
+func TestHistoryPersists(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "history")
+
+	now := time.Now()
+	h, err := LoadHistory(file)
+	assert.Nil(t, err)
+	assert.Nil(t, h.Visit(HistoryEntry{Place: Place{Path: "/notes/a.md", HeadingPath: []string{"Bridge mode"}}, Visited: now.Add(-time.Hour)}))
+	h.Visit(HistoryEntry{Place: Place{Path: "/notes/b.md"}, Visited: now.Add(-time.Minute)})
+	h.Visit(HistoryEntry{Place: Place{Path: "/notes/a.md"}, Visited: now})
+
+	loaded, _ := LoadHistory(file)
+	assert.Equal(t, 3, len(loaded.Entries))
+	assert.Nil(t, loaded.Current())
+	e, ok, err := loaded.Back()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "/notes/a.md", e.Path)
+
+	recent := loaded.Recent(10)
+	assert.Equal(t, 2, len(recent))
+	assert.Equal(t, "/notes/a.md", recent[0].Path)
+	assert.Equal(t, "/notes/b.md", recent[1].Path)
+}
+
+func TestPlaceSection(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h1>Networking</h1><h2>Bridge mode</h2><h3>Ports</h3></body>"))
+	doc := DocumentFromNode(node, "networking")
+
+	assert.Equal(t, "Ports", Place{HeadingPath: []string{"Bridge mode", "Ports"}}.Section(doc).SearchTerm)
+	assert.Equal(t, doc, Place{HeadingPath: []string{"Missing"}}.Section(doc))
+	assert.Equal(t, doc, Place{HeadingPath: []string{"Bridge mode", "Missing"}}.Section(doc))
+}
+
+func TestPlaceSectionIsExact(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h1>Release</h1><h2>Deploy</h2><h3>Staging</h3><h2>Deploy staging</h2></body>"))
+	doc := DocumentFromNode(node, "release")
+
+	section := Place{HeadingPath: []string{"Deploy staging"}}.Section(doc)
+	assert.Equal(t, "Deploy staging", section.SearchTerm)
+	assert.Equal(t, doc, section.Super)
+	assert.Equal(t, "Staging", Place{HeadingPath: []string{"deploy", "staging"}}.Section(doc).SearchTerm)
+}
+
+func TestHistorySaveError(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	h, _ := LoadHistory(filepath.Join(dir, "missing", "history"))
+
+	err := h.Visit(HistoryEntry{Place: Place{Path: "/notes/a.md"}})
+	assert.True(t, util.IsErrorKind(err, util.ErrorFile))
+	// the visit is still recorded
+	assert.Equal(t, 1, len(h.Entries))
+}
+
+func TestHistoryCorruptFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "history")
+	ioutil.WriteFile(file, []byte("[{"), 0644)
+
+	h, err := LoadHistory(file)
+	assert.True(t, util.IsErrorKind(err, util.ErrorFile))
+	assert.Nil(t, h.Current())
+	// the visit is recorded, but the file isn't saved over
+	assert.NotNil(t, h.Visit(HistoryEntry{Place: Place{Path: "/notes/a.md"}}))
+	assert.Equal(t, 1, len(h.Entries))
+	data, _ := ioutil.ReadFile(file)
+	assert.Equal(t, "[{", string(data))
+}
@@ -0,0 +1,194 @@
+package model
+
+import (
+	"sync"
+	"time"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// IndexEntry - a note held in the index, parsed and ready to traverse or search
+type IndexEntry struct {
+	Path    FilePath
+	ModTime time.Time
+	Size    int64
+	File    *File
+	// Err - why the note could not be read or parsed, if it couldn't
+	Err    error
+	Text   string
+	fields *searchFields
+}
+
+func (e *IndexEntry) searchFields() *searchFields {
+	if e.fields == nil {
+		e.fields = makeSearchFields(e)
+	}
+	return e.fields
+}
+
+// IndexChanges - the files added, changed and removed by a refresh of the index
+type IndexChanges struct {
+	Added   []FilePath
+	Changed []FilePath
+	Removed []FilePath
+}
+
+// Loaded - the number of files read and parsed
+func (ic IndexChanges) Loaded() int {
+	return len(ic.Added) + len(ic.Changed)
+}
+
+// IsEmpty ...
+func (ic IndexChanges) IsEmpty() bool {
+	return len(ic.Added) == 0 && len(ic.Changed) == 0 && len(ic.Removed) == 0
+}
+
+// Touches - was the file with the given full path changed or removed
+func (ic IndexChanges) Touches(full string) bool {
+	for _, fps := range [][]FilePath{ic.Changed, ic.Removed} {
+		for _, fp := range fps {
+			if fp.Full == full {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Index - an in-process index of all notes in the search roots.
+// Refreshing only re-reads and re-parses files whose modification time or size changed
+type Index struct {
+	entries map[string]*IndexEntry
+	paths   []FilePath
+	loaded  bool
+	mux     sync.RWMutex
+}
+
+// MakeIndex ...
+func MakeIndex() *Index {
+	return &Index{
+		entries: make(map[string]*IndexEntry),
+		paths:   make([]FilePath, 0),
+	}
+}
+
+// Refresh - update the index to hold exactly the given paths
+func (idx *Index) Refresh(paths []FilePath) IndexChanges {
+	idx.mux.Lock()
+	defer idx.mux.Unlock()
+
+	changes := IndexChanges{}
+	entries := make(map[string]*IndexEntry, len(paths))
+	for _, p := range paths {
+		existing, ok := idx.entries[p.Full]
+		if ok && !entryIsStale(existing, p) {
+			existing.Path = p
+			entries[p.Full] = existing
+			continue
+		}
+		entries[p.Full] = makeIndexEntry(p)
+		if ok {
+			changes.Changed = append(changes.Changed, p)
+		} else {
+			changes.Added = append(changes.Added, p)
+		}
+	}
+	for _, p := range idx.paths {
+		if _, ok := entries[p.Full]; !ok {
+			changes.Removed = append(changes.Removed, p)
+		}
+	}
+
+	idx.entries = entries
+	idx.paths = paths
+	idx.loaded = true
+	return changes
+}
+
+// Update - (re)load a single path, adding it to the index if it isn't there already
+func (idx *Index) Update(p FilePath) {
+	idx.mux.Lock()
+	defer idx.mux.Unlock()
+
+	if _, ok := idx.entries[p.Full]; !ok {
+		idx.paths = append(idx.paths, p)
+	}
+	idx.entries[p.Full] = makeIndexEntry(p)
+}
+
+// Remove - drop a file from the index by its full path
+func (idx *Index) Remove(full string) {
+	idx.mux.Lock()
+	defer idx.mux.Unlock()
+
+	if _, ok := idx.entries[full]; !ok {
+		return
+	}
+	delete(idx.entries, full)
+	paths := make([]FilePath, 0, len(idx.paths))
+	for _, p := range idx.paths {
+		if p.Full != full {
+			paths = append(paths, p)
+		}
+	}
+	idx.paths = paths
+}
+
+// IsLoaded - has the index been populated yet
+func (idx *Index) IsLoaded() bool {
+	idx.mux.RLock()
+	defer idx.mux.RUnlock()
+	return idx.loaded
+}
+
+// Paths - all indexed paths in walk order
+func (idx *Index) Paths() []FilePath {
+	idx.mux.RLock()
+	defer idx.mux.RUnlock()
+	out := make([]FilePath, len(idx.paths))
+	copy(out, idx.paths)
+	return out
+}
+
+// Entries - all index entries in walk order
+func (idx *Index) Entries() []*IndexEntry {
+	idx.mux.RLock()
+	defer idx.mux.RUnlock()
+	out := make([]*IndexEntry, 0, len(idx.paths))
+	for _, p := range idx.paths {
+		out = append(out, idx.entries[p.Full])
+	}
+	return out
+}
+
+// Get - the entry for a full file path, or nil if it isn't indexed
+func (idx *Index) Get(full string) *IndexEntry {
+	idx.mux.RLock()
+	defer idx.mux.RUnlock()
+	return idx.entries[full]
+}
+
+func entryIsStale(e *IndexEntry, p FilePath) bool {
+	if p.FileInfo == nil {
+		return true
+	}
+	return !e.ModTime.Equal(p.FileInfo.ModTime()) || e.Size != p.FileInfo.Size()
+}
+
+func makeIndexEntry(p FilePath) *IndexEntry {
+	f, err := LoadCodeFile(p.Full)
+	e := IndexEntry{
+		Path: p,
+		File: f,
+		Err:  err,
+	}
+	if p.FileInfo != nil {
+		e.ModTime = p.FileInfo.ModTime()
+		e.Size = p.FileInfo.Size()
+	}
+	if e.File.Body != nil {
+		e.Text = util.PlainText(e.File.Body)
+	}
+	e.fields = makeSearchFields(&e)
+	return &e
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+)
+
+func TestIndexRefreshOnlyReloadsChangedFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+
+	writeNote(t, dir, "one.md", "# One\n\nfirst")
+	writeNote(t, dir, "two.md", "# Two\n\nsecond")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+	assert.Equal(t, 2, fm.Reload().Loaded())
+	assert.True(t, fm.Reload().IsEmpty())
+
+	one := fm.Index.Get(filepath.Join(dir, "one.md"))
+	assert.Equal(t, "One\nfirst\n", one.Text)
+	assert.Equal(t, "One", one.File.Document.SearchTerm)
+
+	writeNote(t, dir, "two.md", "# Two\n\nsecond, edited")
+	later := time.Now().Add(time.Second)
+	os.Chtimes(filepath.Join(dir, "two.md"), later, later)
+	writeNote(t, dir, "three.md", "# Three")
+	os.Remove(filepath.Join(dir, "one.md"))
+
+	changes := fm.Reload()
+	assert.Equal(t, 2, changes.Loaded())
+	assert.Equal(t, 1, len(changes.Added))
+	assert.True(t, changes.Touches(filepath.Join(dir, "one.md")))
+	assert.True(t, changes.Touches(filepath.Join(dir, "two.md")))
+	assert.Nil(t, fm.Index.Get(filepath.Join(dir, "one.md")))
+	assert.Equal(t, 2, len(fm.IndexEntries()))
+	assert.Equal(t, "Two\nsecond, edited\n", fm.Index.Get(filepath.Join(dir, "two.md")).Text)
+}
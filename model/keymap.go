@@ -0,0 +1,332 @@
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/constants"
+	"github.com/thomgray/notebee/util"
+)
+
+// Chord - a key pressed with its modifiers, e.g. ctrl-s, alt-left or the character N
+type Chord struct {
+	Key  egg.Key
+	Char rune
+	Mod  egg.Modifier
+}
+
+// named keys, in the order their names are preferred when several keys are the same (e.g. tab and ctrl-i)
+var keyNames = []struct {
+	name string
+	key  egg.Key
+}{
+	{"esc", egg.KeyEsc},
+	{"enter", egg.KeyEnter},
+	{"tab", egg.KeyTab},
+	{"shift-tab", egg.KeyBacktab},
+	{"backspace", egg.KeyBackspace2},
+	{"up", egg.KeyUp},
+	{"down", egg.KeyDown},
+	{"left", egg.KeyLeft},
+	{"right", egg.KeyRight},
+	{"pgup", egg.KeyPgUp},
+	{"pgdn", egg.KeyPgDn},
+	{"home", egg.KeyHome},
+	{"end", egg.KeyEnd},
+	{"delete", egg.KeyDelete},
+	{"ctrl-space", egg.KeyCtrlSpace},
+	{"ctrl-]", egg.KeyCtrlRightSq},
+	{"ctrl-\\", egg.KeyCtrlBackslash},
+}
+
+const altPrefix = "alt-"
+const ctrlPrefix = "ctrl-"
+
+// ParseChord - the chord written as e.g. `ctrl-s`, `alt-left`, `shift-tab`, `f5`, `space` or a single character
+func ParseChord(s string) (Chord, error) {
+	var c Chord
+	rest := s
+	if strings.HasPrefix(strings.ToLower(rest), altPrefix) && len(rest) > len(altPrefix) {
+		c.Mod = egg.ModAlt
+		rest = rest[len(altPrefix):]
+	}
+	if utf8.RuneCountInString(rest) == 1 {
+		c.Key = egg.KeyRune
+		c.Char, _ = utf8.DecodeRuneInString(rest)
+		return c, nil
+	}
+	lower := strings.ToLower(rest)
+	if lower == "space" {
+		c.Key, c.Char = egg.KeyRune, ' '
+		return c, nil
+	}
+	for _, kn := range keyNames {
+		if kn.name == lower {
+			c.Key = kn.key
+			return c, nil
+		}
+	}
+	if strings.HasPrefix(lower, ctrlPrefix) && len(lower) == len(ctrlPrefix)+1 {
+		if l := lower[len(ctrlPrefix)]; l >= 'a' && l <= 'z' {
+			c.Key = egg.KeyCtrlA + egg.Key(l-'a')
+			return c, nil
+		}
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(lower, "f")); strings.HasPrefix(lower, "f") && err == nil && n >= 1 && n <= 64 {
+		c.Key = egg.KeyF1 + egg.Key(n-1)
+		return c, nil
+	}
+	return Chord{}, util.Invalid("unknown key '%s'", s)
+}
+
+// ChordOf - the chord of a key event
+func ChordOf(e *egg.KeyEvent) Chord {
+	c := Chord{Key: e.Key, Mod: e.Mod & egg.ModAlt}
+	if e.Key == egg.KeyRune {
+		c.Char = e.Char
+	}
+	return c
+}
+
+// IsChar - is the chord a printable character, without alt
+func (c Chord) IsChar() bool {
+	return c.Key == egg.KeyRune && c.Mod == 0
+}
+
+func (c Chord) String() string {
+	prefix := ""
+	if c.Mod&egg.ModAlt != 0 {
+		prefix = altPrefix
+	}
+	if c.Key == egg.KeyRune {
+		if c.Char == ' ' {
+			return prefix + "space"
+		}
+		return prefix + string(c.Char)
+	}
+	for _, kn := range keyNames {
+		if kn.key == c.Key {
+			return prefix + kn.name
+		}
+	}
+	if c.Key >= egg.KeyCtrlA && c.Key <= egg.KeyCtrlZ {
+		return prefix + ctrlPrefix + string(rune('a'+c.Key-egg.KeyCtrlA))
+	}
+	if c.Key >= egg.KeyF1 && c.Key <= egg.KeyF64 {
+		return prefix + "f" + strconv.Itoa(int(c.Key-egg.KeyF1)+1)
+	}
+	return prefix + "?"
+}
+
+// Action - something a key can be bound to do
+type Action string
+
+// Actions. What an action does depends on the mode it is bound in, e.g. next selects the next link when
+// selecting links, and goes to the next match when finding
+const (
+	ActionNone           Action = "none"
+	ActionCancel         Action = "cancel"
+	ActionSubmit         Action = "submit"
+	ActionTraverse       Action = "traverse"
+	ActionSearch         Action = "search"
+	ActionCommand        Action = "command"
+	ActionComplete       Action = "complete"
+	ActionSelectLastLink Action = "select-last-link"
+	ActionScrollUp       Action = "scroll-up"
+	ActionScrollDown     Action = "scroll-down"
+	ActionPageUp         Action = "page-up"
+	ActionPageDown       Action = "page-down"
+	ActionFind           Action = "find"
+	ActionTOC            Action = "toc"
+	ActionBack           Action = "back"
+	ActionForward        Action = "forward"
+	ActionNext           Action = "next"
+	ActionPrev           Action = "prev"
+	ActionOpen           Action = "open"
+	ActionAccept         Action = "accept"
+	ActionCollapse       Action = "collapse"
+	ActionExpand         Action = "expand"
+)
+
+// the actions that can be bound in each mode, in the order they are listed
+var modeActions = map[constants.ActiveMode][]Action{
+	constants.ActiveModeDefault: {
+		ActionCancel, ActionSubmit, ActionTraverse, ActionSearch, ActionCommand, ActionComplete, ActionSelectLastLink,
+		ActionScrollUp, ActionScrollDown, ActionPageUp, ActionPageDown, ActionFind, ActionTOC, ActionBack, ActionForward,
+	},
+	constants.ActiveModeAutocomplete:       {ActionCancel, ActionNext, ActionPrev, ActionAccept},
+	constants.ActiveModeSearchResultSelect: {ActionCancel, ActionNext, ActionPrev, ActionOpen},
+	constants.ActiveModeLinkSelect: {
+		ActionCancel, ActionNext, ActionPrev, ActionOpen, ActionScrollUp, ActionScrollDown, ActionPageUp, ActionPageDown,
+	},
+	constants.ActiveModeFind: {
+		ActionCancel, ActionNext, ActionPrev, ActionFind, ActionScrollUp, ActionScrollDown, ActionPageUp, ActionPageDown,
+	},
+	constants.ActiveModeOutline: {ActionCancel, ActionNext, ActionPrev, ActionOpen, ActionCollapse, ActionExpand, ActionTOC},
+}
+
+// KeymapModes - the modes keys are bound in, in the order they are listed
+var KeymapModes = []constants.ActiveMode{
+	constants.ActiveModeDefault,
+	constants.ActiveModeAutocomplete,
+	constants.ActiveModeSearchResultSelect,
+	constants.ActiveModeLinkSelect,
+	constants.ActiveModeFind,
+	constants.ActiveModeOutline,
+}
+
+// Binding - a chord and the action it is bound to
+type Binding struct {
+	Chord  Chord
+	Action Action
+}
+
+// Keymap - the actions bound to chords in each mode
+type Keymap struct {
+	Preset   string
+	bindings map[constants.ActiveMode]map[Chord]Action
+}
+
+// PresetDefault ...
+const PresetDefault = "default"
+
+// MakeKeymap - the bindings of a preset: default, vi or emacs
+func MakeKeymap(preset string) (*Keymap, error) {
+	km := Keymap{Preset: PresetDefault, bindings: make(map[constants.ActiveMode]map[Chord]Action)}
+	km.bindAll(presets[PresetDefault])
+	if preset == "" || preset == PresetDefault {
+		return &km, nil
+	}
+	overrides, ok := presets[preset]
+	if !ok {
+		return &km, util.Invalid("no preset '%s'; use default, vi or emacs", preset)
+	}
+	km.Preset = preset
+	km.bindAll(overrides)
+	return &km, nil
+}
+
+func (km *Keymap) bindAll(bindings presetBindings) {
+	for mode, chords := range bindings {
+		for chord, action := range chords {
+			km.BindChord(mode, chord, action)
+		}
+	}
+}
+
+// keymapFile - the keys file: a preset, and bindings by mode name and chord, e.g.
+// {"Preset": "vi", "Bindings": {"note": {"ctrl-o": "back"}}}
+type keymapFile struct {
+	Preset   string
+	Bindings map[string]map[string]Action
+}
+
+// LoadKeymap - the preset named in the file with the file's bindings over it, or the default keymap if there is no file.
+// Bindings that can't be made are skipped, and the error says why
+func LoadKeymap(file string) (*Keymap, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return MakeKeymap(PresetDefault)
+	} else if err != nil {
+		km, _ := MakeKeymap(PresetDefault)
+		return km, util.FileError(err, "could not read %s", file)
+	}
+	var kf keymapFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		km, _ := MakeKeymap(PresetDefault)
+		return km, util.FileError(err, "%s is not valid JSON", file)
+	}
+
+	km, firstErr := MakeKeymap(kf.Preset)
+	modeNames := make([]string, 0, len(kf.Bindings))
+	for name := range kf.Bindings {
+		modeNames = append(modeNames, name)
+	}
+	sort.Strings(modeNames)
+	for _, name := range modeNames {
+		mode, ok := ModeNamed(name)
+		if !ok {
+			if firstErr == nil {
+				firstErr = util.Invalid("no mode '%s' in %s", name, file)
+			}
+			continue
+		}
+		for chord, action := range kf.Bindings[name] {
+			if err := km.BindChord(mode, chord, action); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return km, firstErr
+}
+
+// ModeNamed - the mode with the name, e.g. find
+func ModeNamed(name string) (constants.ActiveMode, bool) {
+	for _, mode := range KeymapModes {
+		if constants.ActiveModeNames[mode] == name {
+			return mode, true
+		}
+	}
+	return constants.ActiveModeDefault, false
+}
+
+// BindChord - bind the chord, as written for ParseChord, to the action in the mode
+func (km *Keymap) BindChord(mode constants.ActiveMode, chord string, action Action) error {
+	c, err := ParseChord(chord)
+	if err != nil {
+		return err
+	}
+	return km.Bind(mode, c, action)
+}
+
+// Bind - bind the chord to the action in the mode. Binding to ActionNone unbinds the chord
+func (km *Keymap) Bind(mode constants.ActiveMode, c Chord, action Action) error {
+	if action == ActionNone {
+		delete(km.bindings[mode], c)
+		return nil
+	}
+	if actionIndex(mode, action) < 0 {
+		return util.Invalid("'%s' can't be bound in %s mode", action, constants.ActiveModeNames[mode])
+	}
+	if km.bindings[mode] == nil {
+		km.bindings[mode] = make(map[Chord]Action)
+	}
+	km.bindings[mode][c] = action
+	return nil
+}
+
+// Action - the action bound to the chord in the mode, or "" if there is none
+func (km *Keymap) Action(mode constants.ActiveMode, c Chord) Action {
+	return km.bindings[mode][c]
+}
+
+// Bindings - the bindings in the mode, in the order of their actions
+func (km *Keymap) Bindings(mode constants.ActiveMode) []Binding {
+	res := make([]Binding, 0, len(km.bindings[mode]))
+	for c, a := range km.bindings[mode] {
+		res = append(res, Binding{Chord: c, Action: a})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		ai, aj := actionIndex(mode, res[i].Action), actionIndex(mode, res[j].Action)
+		if ai != aj {
+			return ai < aj
+		}
+		return res[i].Chord.String() < res[j].Chord.String()
+	})
+	return res
+}
+
+func actionIndex(mode constants.ActiveMode, action Action) int {
+	for i, a := range modeActions[mode] {
+		if a == action {
+			return i
+		}
+	}
+	return -1
+}
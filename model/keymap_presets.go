@@ -0,0 +1,157 @@
+package model
+
+import "github.com/thomgray/notebee/constants"
+
+// presetBindings - actions by mode and chord, as written for ParseChord
+type presetBindings map[constants.ActiveMode]map[string]Action
+
+// presets - the default bindings, and the bindings vi and emacs users would expect, which are added over them
+var presets = map[string]presetBindings{
+	PresetDefault: {
+		constants.ActiveModeDefault: {
+			"esc":       ActionCancel,
+			"enter":     ActionSubmit,
+			">":         ActionTraverse,
+			"?":         ActionSearch,
+			":":         ActionCommand,
+			"tab":       ActionComplete,
+			"shift-tab": ActionSelectLastLink,
+			"up":        ActionScrollUp,
+			"down":      ActionScrollDown,
+			"pgup":      ActionPageUp,
+			"pgdn":      ActionPageDown,
+			"ctrl-s":    ActionFind,
+			"ctrl-t":    ActionTOC,
+			"ctrl-b":    ActionBack,
+			"ctrl-f":    ActionForward,
+			"alt-left":  ActionBack,
+			"alt-right": ActionForward,
+		},
+		constants.ActiveModeAutocomplete: {
+			"esc":       ActionCancel,
+			"tab":       ActionNext,
+			"down":      ActionNext,
+			"shift-tab": ActionPrev,
+			"up":        ActionPrev,
+			"enter":     ActionAccept,
+		},
+		constants.ActiveModeSearchResultSelect: {
+			"esc":       ActionCancel,
+			"tab":       ActionNext,
+			"down":      ActionNext,
+			"shift-tab": ActionPrev,
+			"up":        ActionPrev,
+			"enter":     ActionOpen,
+		},
+		constants.ActiveModeLinkSelect: {
+			"esc":       ActionCancel,
+			"tab":       ActionNext,
+			"shift-tab": ActionPrev,
+			"enter":     ActionOpen,
+			"up":        ActionScrollUp,
+			"down":      ActionScrollDown,
+			"pgup":      ActionPageUp,
+			"pgdn":      ActionPageDown,
+		},
+		constants.ActiveModeFind: {
+			"esc":   ActionCancel,
+			"n":     ActionNext,
+			"enter": ActionNext,
+			"N":     ActionPrev,
+			"/":     ActionFind,
+			"up":    ActionScrollUp,
+			"down":  ActionScrollDown,
+			"pgup":  ActionPageUp,
+			"pgdn":  ActionPageDown,
+		},
+		constants.ActiveModeOutline: {
+			"esc":    ActionCancel,
+			"down":   ActionNext,
+			"up":     ActionPrev,
+			"enter":  ActionOpen,
+			"left":   ActionCollapse,
+			"right":  ActionExpand,
+			"ctrl-t": ActionTOC,
+		},
+	},
+	"vi": {
+		constants.ActiveModeDefault: {
+			"ctrl-e": ActionScrollDown,
+			"ctrl-y": ActionScrollUp,
+			"ctrl-d": ActionPageDown,
+			"ctrl-u": ActionPageUp,
+			"ctrl-f": ActionPageDown,
+			"ctrl-b": ActionPageUp,
+			"ctrl-o": ActionBack,
+		},
+		constants.ActiveModeAutocomplete: {
+			"ctrl-n": ActionNext,
+			"ctrl-p": ActionPrev,
+		},
+		constants.ActiveModeSearchResultSelect: {
+			"ctrl-n": ActionNext,
+			"ctrl-p": ActionPrev,
+			"j":      ActionNext,
+			"k":      ActionPrev,
+		},
+		constants.ActiveModeLinkSelect: {
+			"j":      ActionScrollDown,
+			"k":      ActionScrollUp,
+			"ctrl-d": ActionPageDown,
+			"ctrl-u": ActionPageUp,
+		},
+		constants.ActiveModeFind: {
+			"j":      ActionScrollDown,
+			"k":      ActionScrollUp,
+			"ctrl-d": ActionPageDown,
+			"ctrl-u": ActionPageUp,
+		},
+		constants.ActiveModeOutline: {
+			"j": ActionNext,
+			"k": ActionPrev,
+			"h": ActionCollapse,
+			"l": ActionExpand,
+		},
+	},
+	"emacs": {
+		constants.ActiveModeDefault: {
+			"ctrl-g": ActionCancel,
+			"ctrl-n": ActionScrollDown,
+			"ctrl-p": ActionScrollUp,
+			"ctrl-v": ActionPageDown,
+			"alt-v":  ActionPageUp,
+			"alt-,":  ActionBack,
+		},
+		constants.ActiveModeAutocomplete: {
+			"ctrl-g": ActionCancel,
+			"ctrl-n": ActionNext,
+			"ctrl-p": ActionPrev,
+		},
+		constants.ActiveModeSearchResultSelect: {
+			"ctrl-g": ActionCancel,
+			"ctrl-n": ActionNext,
+			"ctrl-p": ActionPrev,
+		},
+		constants.ActiveModeLinkSelect: {
+			"ctrl-g": ActionCancel,
+			"ctrl-n": ActionNext,
+			"ctrl-p": ActionPrev,
+			"ctrl-v": ActionPageDown,
+			"alt-v":  ActionPageUp,
+		},
+		constants.ActiveModeFind: {
+			"ctrl-g": ActionCancel,
+			"ctrl-s": ActionNext,
+			"ctrl-r": ActionPrev,
+			"ctrl-v": ActionPageDown,
+			"alt-v":  ActionPageUp,
+		},
+		constants.ActiveModeOutline: {
+			"ctrl-g": ActionCancel,
+			"ctrl-n": ActionNext,
+			"ctrl-p": ActionPrev,
+			"ctrl-b": ActionCollapse,
+			"ctrl-f": ActionExpand,
+		},
+	},
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/constants"
+	"github.com/thomgray/notebee/util"
+)
+
+func TestParseChord(t *testing.T) {
+	for _, s := range []string{"ctrl-s", "alt-left", "shift-tab", "enter", "f5", "space", "n", "N", "/", "alt-v"} {
+		c, err := ParseChord(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, c.String())
+	}
+
+	c, _ := ParseChord("Ctrl-S")
+	assert.Equal(t, Chord{Key: egg.KeyCtrlS}, c)
+	c, _ = ParseChord("ctrl-i")
+	assert.Equal(t, "tab", c.String())
+	assert.True(t, Chord{Key: egg.KeyRune, Char: 'n'}.IsChar())
+
+	_, err := ParseChord("hyper-x")
+	assert.True(t, util.IsErrorKind(err, util.ErrorInvalid))
+}
+
+func TestChordOf(t *testing.T) {
+	assert.Equal(t, Chord{Key: egg.KeyLeft, Mod: egg.ModAlt}, ChordOf(&egg.KeyEvent{Key: egg.KeyLeft, Mod: egg.ModAlt}))
+	assert.Equal(t, Chord{Key: egg.KeyEnter}, ChordOf(&egg.KeyEvent{Key: egg.KeyEnter, Char: '\r'}))
+}
+
+func TestPresets(t *testing.T) {
+	for _, preset := range []string{PresetDefault, "vi", "emacs"} {
+		km, err := MakeKeymap(preset)
+		assert.NoError(t, err)
+		// every binding in the presets is one that can be made
+		for mode, chords := range presets[preset] {
+			for chord, action := range chords {
+				assert.NoError(t, km.BindChord(mode, chord, action), "%s %s %s", preset, chord, action)
+			}
+		}
+	}
+
+	vi, _ := MakeKeymap("vi")
+	assert.Equal(t, ActionNext, vi.Action(constants.ActiveModeOutline, Chord{Key: egg.KeyRune, Char: 'j'}))
+	assert.Equal(t, ActionNext, vi.Action(constants.ActiveModeOutline, Chord{Key: egg.KeyDown}))
+	assert.Equal(t, ActionPageDown, vi.Action(constants.ActiveModeDefault, Chord{Key: egg.KeyCtrlF}))
+
+	km, err := MakeKeymap("nano")
+	assert.Error(t, err)
+	assert.Equal(t, PresetDefault, km.Preset)
+}
+
+func TestLoadKeymap(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "keys")
+
+	km, err := LoadKeymap(file)
+	assert.NoError(t, err)
+	assert.Equal(t, ActionFind, km.Action(constants.ActiveModeDefault, Chord{Key: egg.KeyCtrlS}))
+
+	ioutil.WriteFile(file, []byte(`{
+		"Preset": "emacs",
+		"Bindings": {
+			"note": {"ctrl-o": "back", "ctrl-s": "none"},
+			"find": {"x": "collapse"}
+		}
+	}`), 0644)
+	km, err = LoadKeymap(file)
+	assert.Equal(t, "'collapse' can't be bound in find mode", err.Error())
+	assert.Equal(t, "emacs", km.Preset)
+	assert.Equal(t, ActionBack, km.Action(constants.ActiveModeDefault, Chord{Key: egg.KeyCtrlO}))
+	assert.Equal(t, Action(""), km.Action(constants.ActiveModeDefault, Chord{Key: egg.KeyCtrlS}))
+	assert.Equal(t, ActionNext, km.Action(constants.ActiveModeFind, Chord{Key: egg.KeyCtrlS}))
+
+	bindings := km.Bindings(constants.ActiveModeFind)
+	assert.Equal(t, ActionCancel, bindings[0].Action)
+}
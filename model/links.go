@@ -0,0 +1,155 @@
+package model
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/thomgray/notebee/util"
+	"golang.org/x/net/html"
+)
+
+// LinkTarget - the note, and optionally the heading within it, that a link leads to
+type LinkTarget struct {
+	Location *Location
+	// Section - the heading named by the link's fragment, nil if there is none
+	Section *Document
+}
+
+// IsExternalLink - does the href lead outside the notes, e.g. to a web page or an email address
+func IsExternalLink(href string) bool {
+	u, err := url.Parse(href)
+	return err == nil && u.Scheme != "" && u.Scheme != "file"
+}
+
+// SplitLinkFragment - split a href into its path and its #fragment, unescaping both
+func SplitLinkFragment(href string) (string, string) {
+	path, fragment := href, ""
+	if i := strings.Index(href, "#"); i >= 0 {
+		path, fragment = href[:i], href[i+1:]
+	}
+	if p, err := url.PathUnescape(path); err == nil {
+		path = p
+	}
+	if f, err := url.PathUnescape(fragment); err == nil {
+		fragment = f
+	}
+	return path, fragment
+}
+
+// ResolveLink - find the note a relative link in a file leads to, and the heading its fragment names.
+// Paths are relative to the directory of the file, and an empty path (`#heading`) is the file itself
+func (fm *FileManager) ResolveLink(from *File, href string) (*LinkTarget, error) {
+	if IsExternalLink(href) {
+		return nil, util.Invalid("%s is not a link to a note", href)
+	}
+	path, fragment := SplitLinkFragment(href)
+
+	var loc *Location
+	var err error
+	if path == "" {
+		if from == nil {
+			return nil, util.Invalid("no note to follow %s within", href)
+		}
+		loc, err = fm.LocationOfFile(from.Path)
+	} else {
+		full := path
+		if !filepath.IsAbs(full) && from != nil {
+			full = filepath.Join(filepath.Dir(from.Path), path)
+		}
+		if info, ok := util.PathExists(full); !ok || info == nil {
+			return nil, util.NotFound("%s does not exist", path)
+		} else if !isSupportedFile(info) {
+			return nil, util.Invalid("%s is not a note", path)
+		}
+		loc, err = fm.LocationOfFile(filepath.Clean(full))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if loc.File.Document == nil {
+		return nil, util.Invalid("%s is not a note", href)
+	}
+
+	target := &LinkTarget{Location: loc}
+	if fragment != "" {
+		target.Section = loc.File.Document.FindAnchor(fragment)
+		if target.Section == nil {
+			return nil, util.NotFound("no heading #%s in %s", fragment, loc.RelativePathWithName)
+		}
+	}
+	return target, nil
+}
+
+// LocationOfFile - the location of a note at the top of its document, by its full path.
+// Notes outside the search roots are relative to their own directory
+func (fm *FileManager) LocationOfFile(full string) (*Location, error) {
+	fm.IndexEntries()
+	if e := fm.Index.Get(full); e != nil && e.File != nil {
+		if e.Err != nil {
+			return nil, e.Err
+		}
+		return makeLocation(e.Path, e.File, e.File.Document), nil
+	}
+	f, err := LoadCodeFile(full)
+	if err != nil {
+		return nil, err
+	}
+	if f.Document == nil {
+		return nil, util.Invalid("%s is not a note", full)
+	}
+	p := FilePath{Full: full, BaseDir: filepath.Dir(full), Relative: filepath.Base(full)}
+	return makeLocation(p, f, f.Document), nil
+}
+
+// QueryPathOf - the query path of a note by its full path, or its name if it is outside the search roots
+func (fm *FileManager) QueryPathOf(full string) string {
+	if e := fm.Index.Get(full); e != nil {
+		return e.Path.QueryPath()
+	}
+	return FilePath{Relative: filepath.Base(full)}.QueryPath()
+}
+
+// FindAnchor - the heading within this document that a link fragment refers to,
+// by the heading's id or by its anchor as GitHub generates them (`#bridge-mode`)
+func (doc *Document) FindAnchor(fragment string) *Document {
+	for _, sub := range doc.SubDocuments {
+		if id, ok := nodeID(sub.Node); ok && id == fragment {
+			return sub
+		}
+		if HeadingAnchor(sub.SearchTerm) == strings.ToLower(fragment) {
+			return sub
+		}
+		if found := sub.FindAnchor(fragment); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// HeadingAnchor - the fragment linking to a heading: lower case, punctuation dropped and spaces as hyphens
+func HeadingAnchor(title string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
+
+func nodeID(n *html.Node) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	for _, a := range n.Attr {
+		if a.Key == "id" {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+)
+
+func TestIsExternalLink(t *testing.T) {
+	assert.True(t, IsExternalLink("https://kubernetes.io/docs"))
+	assert.True(t, IsExternalLink("mailto:someone@example.com"))
+	assert.False(t, IsExternalLink("../k8s/pods.md#probes"))
+	assert.False(t, IsExternalLink("#probes"))
+}
+
+func TestHeadingAnchor(t *testing.T) {
+	assert.Equal(t, "liveness-probes", HeadingAnchor("Liveness probes"))
+	assert.Equal(t, "whats-new-in-v12", HeadingAnchor("What's new in v1.2?"))
+	assert.Equal(t, "snake_case", HeadingAnchor(" snake_case "))
+}
+
+func TestResolveLink(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	writeNote(t, dir, "k8s/pods.md", "# Pods\n\n## Probes\n\n### Liveness probes\n\n## Volumes {#storage}\n")
+	writeNote(t, dir, "docker/networking.md", "# Networking\n\nSee [probes](../k8s/pods.md#liveness-probes)\n")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+	from, _ := fm.LoadFile(FilePath{Full: filepath.Join(dir, "docker/networking.md")})
+
+	target, err := fm.ResolveLink(from, "../k8s/pods.md#liveness-probes")
+	assert.Nil(t, err)
+	assert.Equal(t, "k8s/pods", target.Location.RelativePathWithName)
+	assert.Equal(t, "Liveness probes", target.Section.SearchTerm)
+
+	target, err = fm.ResolveLink(from, "../k8s/pods.md")
+	assert.Nil(t, err)
+	assert.Nil(t, target.Section)
+
+	pods := target.Location.File
+	target, err = fm.ResolveLink(pods, "#storage")
+	assert.Nil(t, err)
+	assert.Equal(t, "Volumes", target.Section.SearchTerm)
+
+	_, err = fm.ResolveLink(from, "../k8s/pods.md#missing")
+	assert.NotNil(t, err)
+	_, err = fm.ResolveLink(from, "../k8s/deployments.md")
+	assert.NotNil(t, err)
+	_, err = fm.ResolveLink(from, "https://kubernetes.io")
+	assert.NotNil(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/thomgray/notebee/util"
 	"golang.org/x/net/html"
 )
 
@@ -55,6 +56,7 @@ type ContentSegment struct {
 }
 
 type Element struct {
+	Node        *html.Node
 	Type        ElementType
 	Tag         string
 	Content     []*ContentSegment
@@ -67,7 +69,13 @@ func DocumentFromNode(n *html.Node, filename string) *Document {
 	d.Node = n
 	els := make([]*Element, 0)
 
-	for node := n.FirstChild; node != nil; node = node.NextSibling {
+	// accept either a full html document or the body itself
+	body := n
+	if b := util.HTMLBody(n); b != nil {
+		body = b
+	}
+
+	for node := body.FirstChild; node != nil; node = node.NextSibling {
 		e := parseElement(node, false)
 		d.Content = append(d.Content, node)
 		if e != nil {
@@ -94,8 +102,10 @@ func DocumentFromNode(n *html.Node, filename string) *Document {
 	}
 
 	d.Elements = els
-	if len(els) > 1 {
-		d.SubDocuments = extractDocuments(els[1:], &d)
+	if d.Heading.Tag == "h1" {
+		d.SubDocuments = extractDocuments(els[1:], &d, nil)
+	} else {
+		d.SubDocuments = extractDocuments(els, &d, nil)
 	}
 	return &d
 }
@@ -124,7 +134,8 @@ func zipDocumentsNodes(nodes []*html.Node, hval int) []*Document {
 	return out
 }
 
-func zipDocumentAgain(els []*Element, i int) (*Document, int) {
+// end is the node that terminates the enclosing document (nil if it runs to the end)
+func zipDocumentAgain(els []*Element, i int, end *html.Node) (*Document, int) {
 	if len(els) <= i || els[i].Type != ElementTypeHeading {
 		return nil, i + 1
 	}
@@ -143,19 +154,34 @@ func zipDocumentAgain(els []*Element, i int) (*Document, int) {
 	}
 
 	thisDocEls := els[i:j]
+	if j < len(els) {
+		end = els[j].Node
+	}
 	doc := Document{
+		Node:       el.Node,
+		Content:    siblingsUntil(el.Node, end),
 		Heading:    el,
 		Elements:   thisDocEls,
 		SearchTerm: el.Context[ContextSearchTerm],
 	}
-	doc.SubDocuments = extractDocuments(thisDocEls[1:], &doc)
+	doc.SubDocuments = extractDocuments(thisDocEls[1:], &doc, end)
 	return &doc, j
 }
 
-func extractDocuments(els []*Element, doc *Document) []*Document {
+// returns the node and its following siblings up to (not including) end.
+// this keeps nodes that don't parse to an element (tables etc.) in the section
+func siblingsUntil(start, end *html.Node) []*html.Node {
+	out := make([]*html.Node, 0)
+	for n := start; n != nil && n != end; n = n.NextSibling {
+		out = append(out, n)
+	}
+	return out
+}
+
+func extractDocuments(els []*Element, doc *Document, end *html.Node) []*Document {
 	res := make([]*Document, 0)
 	for i := 0; i < len(els); {
-		d, j := zipDocumentAgain(els, i)
+		d, j := zipDocumentAgain(els, i, end)
 		if d != nil {
 			d.Super = doc
 			res = append(res, d)
@@ -198,13 +224,20 @@ func parseElement(n *html.Node, includingText bool) *Element {
 				e.Content = parsePlainContent(code)
 			}
 		case "blockquote":
-			children := childElements(n)
-			if len(children) == 1 && children[0].Data == "p" {
-				pEl := children[0]
-				e = &Element{}
-				e.Tag = "blockquote"
-				e.Type = ElementTypeQuote
-				e.Content = parsePlainContent(pEl)
+			e = &Element{}
+			e.Tag = "blockquote"
+			e.Type = ElementTypeQuote
+			e.Content = parsePlainContent(n)
+			// paragraphs, lists, code and nested quotes
+			subE := make([]*Element, 0)
+			for _, c := range childElements(n) {
+				if childE := parseElement(c, false); childE != nil {
+					subE = append(subE, childE)
+				}
+			}
+			e.SubElements = subE
+			if _, kind, ok := AdmonitionMarker(n); ok {
+				e.Context = map[string]string{ContextAdmonition: string(kind)}
 			}
 		case "ul":
 			children := childElements(n)
@@ -239,6 +272,9 @@ func parseElement(n *html.Node, includingText bool) *Element {
 		default:
 			log.Printf("Whaaat? %s\n", n.Data)
 		}
+		if e != nil {
+			e.Node = n
+		}
 	} else if includingText && n.Type == html.TextNode {
 		// we parse this element as if it were a <p>.
 		// this will be the case for parsing <li> content with only text content
@@ -353,8 +389,32 @@ func parsePlainContent(n *html.Node) []*ContentSegment {
 // 	return nil
 // }
 
+// Traverse - find the sub document matching the query words, where each heading
+// consumes as many words as it has. An empty query matches this document
+func (doc *Document) Traverse(query []string) *Document {
+	if len(query) == 0 {
+		return doc
+	}
+	for _, sub := range doc.SubDocuments {
+		headingWords := util.StringSplitFlat(sub.SearchTerm)
+		if len(headingWords) == 0 {
+			continue
+		}
+		if is, remainder := util.IsCaseInsensitiveStringSubslice(query, headingWords, false); is {
+			if found := sub.Traverse(remainder); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// IsSection - is this a sub document of a file rather than the file itself
+func (doc *Document) IsSection() bool {
+	return doc.Super != nil
+}
+
 func (doc *Document) SubQueries() [][]string {
-	log.Println(doc.Heading.Context)
 	st := doc.SearchTerm
 	// "this" heading is one query
 	// then prepend to all sub-doc queries
@@ -367,3 +427,61 @@ func (doc *Document) SubQueries() [][]string {
 	}
 	return res
 }
+
+// CompleteSubQuery - complete a partially typed heading query relative to this document.
+// Each completion extends the typed query up to the end of the next heading
+func (doc *Document) CompleteSubQuery(typed string) []string {
+	res := make([]string, 0)
+	typedWords := util.StringSplitFlat(typed)
+	typedNorm := strings.ToLower(strings.Join(typedWords, " "))
+	if strings.HasSuffix(typed, " ") && typedNorm != "" {
+		typedNorm += " "
+	}
+
+	for _, q := range doc.SubQueries()[1:] {
+		// drop this document's own term
+		q = q[1:]
+		for k := 1; k <= len(q); k++ {
+			candidate := strings.Join(util.StringSplitFlat(strings.Join(q[:k], " ")), " ")
+			if len(candidate) < len(typedNorm) {
+				continue
+			}
+			if strings.HasPrefix(strings.ToLower(candidate), typedNorm) && !util.StringSliceContains(res, candidate) {
+				res = append(res, candidate)
+			}
+			break
+		}
+	}
+	return res
+}
+
+// HeadingTitles - the headings from the top of the file down to this document, as written
+func (doc *Document) HeadingTitles() []string {
+	res := make([]string, 0)
+	for d := doc; d != nil && d.Super != nil; d = d.Super {
+		res = append([]string{d.SearchTerm}, res...)
+	}
+	return res
+}
+
+// OwnContent - the content belonging directly to this document, up to its first sub document
+func (doc *Document) OwnContent() []*html.Node {
+	if len(doc.SubDocuments) > 0 {
+		for i, n := range doc.Content {
+			if n == doc.SubDocuments[0].Node {
+				return doc.Content[:i]
+			}
+		}
+	}
+	return doc.Content
+}
+
+// HeadingPath - the heading search terms from the top of the file down to this document,
+// as words that will traverse back to it
+func (doc *Document) HeadingPath() []string {
+	res := make([]string, 0)
+	for d := doc; d != nil && d.Super != nil; d = d.Super {
+		res = append(util.StringSplitFlat(d.SearchTerm), res...)
+	}
+	return res
+}
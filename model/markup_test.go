@@ -39,3 +39,31 @@ func TestZip(t *testing.T) {
 	doc := DocumentFromNode(node, "file")
 	assert.Equal(t, 4, len(doc.Elements))
 }
+
+func TestTraverse(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h1>Rebase</h1><p>intro</p><h2>Interactive</h2><p>i</p><h3>Squash</h3><p>s</p><table></table><h2>Onto</h2><p>o</p></body>"))
+	doc := DocumentFromNode(node, "rebase")
+
+	assert.Equal(t, doc, doc.Traverse([]string{}))
+
+	squash := doc.Traverse([]string{"interactive", "squash"})
+	assert.NotNil(t, squash)
+	assert.Equal(t, "Squash", squash.SearchTerm)
+	assert.True(t, squash.IsSection())
+	// unparsed nodes are kept within the section content
+	assert.Equal(t, 3, len(squash.Content))
+	assert.Equal(t, "table", squash.Content[2].Data)
+
+	interactive := doc.Traverse([]string{"Interactive"})
+	assert.Equal(t, 5, len(interactive.Content))
+
+	assert.Nil(t, doc.Traverse([]string{"interactive", "onto"}))
+}
+
+func TestSubDocumentsWithoutTitle(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h2>One</h2><p>1</p><h2>Two</h2></body>"))
+	doc := DocumentFromNode(node, "file")
+
+	assert.Equal(t, "file", doc.SearchTerm)
+	assert.Equal(t, 2, len(doc.SubDocuments))
+}
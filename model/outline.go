@@ -0,0 +1,51 @@
+package model
+
+import "golang.org/x/net/html"
+
+// OutlineEntry - a heading in the outline of a document, and how deeply it is nested
+type OutlineEntry struct {
+	Document    *Document
+	Depth       int
+	HasChildren bool
+}
+
+// Outline - the headings under the document, depth first. The headings under collapsed documents are left out
+func (doc *Document) Outline(collapsed func(*Document) bool) []OutlineEntry {
+	res := make([]OutlineEntry, 0)
+	var walk func(d *Document, depth int)
+	walk = func(d *Document, depth int) {
+		for _, sub := range d.SubDocuments {
+			res = append(res, OutlineEntry{sub, depth, len(sub.SubDocuments) > 0})
+			if collapsed == nil || !collapsed(sub) {
+				walk(sub, depth+1)
+			}
+		}
+	}
+	if doc != nil {
+		walk(doc, 0)
+	}
+	return res
+}
+
+// IsWithin - is this the other document or one of its sub documents
+func (doc *Document) IsWithin(other *Document) bool {
+	for d := doc; d != nil; d = d.Super {
+		if d == other {
+			return true
+		}
+	}
+	return false
+}
+
+// FindHeading - the sub document with the heading node, at any depth
+func (doc *Document) FindHeading(n *html.Node) *Document {
+	for _, sub := range doc.SubDocuments {
+		if sub.Node == n {
+			return sub
+		}
+		if found := sub.FindHeading(n); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestOutline(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h1>Networking</h1><h2>Bridge mode</h2><h3>Ports</h3><h3>DNS</h3><h2>Host mode</h2></body>"))
+	doc := DocumentFromNode(node, "networking")
+
+	titles := func(entries []OutlineEntry) []string {
+		res := make([]string, 0)
+		for _, e := range entries {
+			res = append(res, strings.Repeat(" ", e.Depth)+e.Document.SearchTerm)
+		}
+		return res
+	}
+	outline := doc.Outline(nil)
+	assert.Equal(t, []string{"Bridge mode", " Ports", " DNS", "Host mode"}, titles(outline))
+	assert.True(t, outline[0].HasChildren)
+	assert.False(t, outline[1].HasChildren)
+
+	bridge := outline[0].Document
+	collapsed := doc.Outline(func(d *Document) bool { return d == bridge })
+	assert.Equal(t, []string{"Bridge mode", "Host mode"}, titles(collapsed))
+
+	ports := outline[1].Document
+	assert.Equal(t, ports, doc.FindHeading(ports.Node))
+	assert.True(t, ports.IsWithin(bridge))
+	assert.True(t, ports.IsWithin(doc))
+	assert.False(t, ports.IsWithin(outline[3].Document))
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+)
+
+// Place - somewhere in the notes: the note, the section of it displayed and how far it was scrolled
+type Place struct {
+	Path string
+	// HeadingPath - the titles of the headings down to the section displayed
+	HeadingPath []string
+	Scroll      int
+}
+
+// Section - the section of the note's document displayed, or the whole document if it no longer exists
+func (p Place) Section(doc *Document) *Document {
+	section := doc
+	for _, title := range p.HeadingPath {
+		section = subDocumentTitled(section, title)
+		if section == nil {
+			return doc
+		}
+	}
+	return section
+}
+
+func subDocumentTitled(doc *Document, title string) *Document {
+	for _, sub := range doc.SubDocuments {
+		if strings.EqualFold(sub.SearchTerm, title) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// same - is this the same section of the same note, however it is scrolled
+func (p Place) same(other Place) bool {
+	if p.Path != other.Path || len(p.HeadingPath) != len(other.HeadingPath) {
+		return false
+	}
+	for i, h := range p.HeadingPath {
+		if h != other.HeadingPath[i] {
+			return false
+		}
+	}
+	return true
+}
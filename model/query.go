@@ -0,0 +1,279 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// Query fields, given as a `field:` prefix on a clause
+const (
+	QueryFieldAny     = ""
+	QueryFieldTitle   = "title"
+	QueryFieldHeading = "heading"
+	QueryFieldCode    = "code"
+	QueryFieldPath    = "path"
+	QueryFieldTag     = "tag"
+)
+
+var queryFields = []string{QueryFieldTitle, QueryFieldHeading, QueryFieldCode, QueryFieldPath, QueryFieldTag}
+
+// queryOr - separates alternative groups of clauses
+const queryOr = "OR"
+
+// QueryClause - a single condition, e.g. `-title:"exact phrase"`
+type QueryClause struct {
+	Negate bool
+	Field  string
+	Value  string
+	// Terms - the value as lower case words, which must match consecutively
+	Terms []string
+	// Exact - every term must match a whole word. Otherwise the last term may match the start of a word
+	Exact bool
+}
+
+// Query - groups of clauses separated by OR. A note matches if it matches every clause of any group
+type Query struct {
+	Groups [][]QueryClause
+}
+
+// ParseQuery - parse the `?` query language:
+// words and "exact phrases", optionally prefixed with `-` to exclude and/or a field (title:, heading:, code:, path:, tag:),
+// with groups of clauses separated by OR
+func ParseQuery(s string) Query {
+	q := Query{}
+	group := make([]QueryClause, 0)
+	rs := []rune(s)
+
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+		clause, next := parseClause(rs, i)
+		i = next
+		if !clause.Negate && clause.Field == QueryFieldAny && !clause.Exact && clause.Value == queryOr {
+			if len(group) > 0 {
+				q.Groups = append(q.Groups, group)
+			}
+			group = make([]QueryClause, 0)
+			continue
+		}
+		if clause.Value != "" {
+			group = append(group, clause)
+		}
+	}
+	if len(group) > 0 {
+		q.Groups = append(q.Groups, group)
+	}
+	return q
+}
+
+func parseClause(rs []rune, i int) (QueryClause, int) {
+	clause := QueryClause{}
+	if rs[i] == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) {
+		clause.Negate = true
+		i++
+	}
+	for _, field := range queryFields {
+		prefix := []rune(field + ":")
+		if i+len(prefix) <= len(rs) && strings.EqualFold(string(rs[i:i+len(prefix)]), string(prefix)) {
+			clause.Field = field
+			i += len(prefix)
+			break
+		}
+	}
+
+	start := i
+	if i < len(rs) && rs[i] == '"' {
+		clause.Exact = true
+		i++
+		start = i
+		for i < len(rs) && rs[i] != '"' {
+			i++
+		}
+		clause.Value = string(rs[start:i])
+		i++ // closing quote
+	} else {
+		for i < len(rs) && !unicode.IsSpace(rs[i]) {
+			i++
+		}
+		clause.Value = string(rs[start:i])
+	}
+	clause.Terms = Tokenise(clause.Value)
+	return clause, i
+}
+
+// HighlightTerms - the terms of the query that are being looked for, to highlight in results
+func (q Query) HighlightTerms() []string {
+	res := make([]string, 0)
+	for _, group := range q.Groups {
+		for _, c := range group {
+			if c.Negate || c.Field == QueryFieldPath || c.Field == QueryFieldTag {
+				continue
+			}
+			for _, t := range c.Terms {
+				if !util.StringSliceContains(res, t) {
+					res = append(res, t)
+				}
+			}
+		}
+	}
+	return res
+}
+
+func (q Query) score(e *IndexEntry) (int, bool) {
+	if q.IsEmpty() {
+		return 0, false
+	}
+	return q.evaluate(e.searchFields())
+}
+
+func (q Query) highlighter() Highlighter {
+	return TermHighlighter(q.HighlightTerms())
+}
+
+// IsEmpty - does the query have nothing to match
+func (q Query) IsEmpty() bool {
+	return len(q.Groups) == 0
+}
+
+// searchFields - the parts of a note a query is evaluated against
+type searchFields struct {
+	path     string
+	title    []token
+	headings []token
+	code     []token
+	body     []token
+	tags     []string
+}
+
+var tagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+
+func makeSearchFields(e *IndexEntry) *searchFields {
+	f := searchFields{
+		path:  strings.ToLower(e.Path.QueryPath()),
+		title: tokenSpans(e.Path.QueryPath()),
+		body:  tokenSpans(e.Text),
+		tags:  make([]string, 0),
+	}
+	if doc := e.File.Document; doc != nil {
+		f.title = append(f.title, tokenSpans(doc.SearchTerm)...)
+		f.headings = tokenSpans(strings.Join(documentHeadings(doc), "\n"))
+		f.code = tokenSpans(strings.Join(codeBlocks(doc.Elements), "\n"))
+	}
+	for _, m := range tagPattern.FindAllStringSubmatch(e.Text, -1) {
+		tag := strings.ToLower(m[1])
+		if !util.StringSliceContains(f.tags, tag) {
+			f.tags = append(f.tags, tag)
+		}
+	}
+	return &f
+}
+
+// the text of fenced code blocks, including those nested in lists
+func codeBlocks(els []*Element) []string {
+	res := make([]string, 0)
+	for _, el := range els {
+		if el.Type == ElementTypeCode {
+			for _, c := range el.Content {
+				res = append(res, c.Raw)
+			}
+		}
+		res = append(res, codeBlocks(el.SubElements)...)
+	}
+	return res
+}
+
+// evaluate - the score of the note for the query, and whether it matches at all
+func (q Query) evaluate(f *searchFields) (int, bool) {
+	score := 0
+	matched := false
+	for _, group := range q.Groups {
+		if groupScore, ok := evaluateGroup(group, f); ok {
+			score += groupScore
+			matched = true
+		}
+	}
+	return score, matched
+}
+
+func evaluateGroup(group []QueryClause, f *searchFields) (int, bool) {
+	score := 0
+	positive := false
+	for _, c := range group {
+		clauseScore := c.score(f)
+		if c.Negate {
+			if clauseScore > 0 {
+				return 0, false
+			}
+			continue
+		}
+		if clauseScore == 0 {
+			return 0, false
+		}
+		positive = true
+		score += clauseScore
+	}
+	// a group of only exclusions doesn't match anything by itself
+	return score, positive
+}
+
+func (c QueryClause) score(f *searchFields) int {
+	switch c.Field {
+	case QueryFieldTitle:
+		return c.countMatches(f.title) * searchWeightTitle
+	case QueryFieldHeading:
+		return c.countMatches(f.headings) * searchWeightHeading
+	case QueryFieldCode:
+		return c.countMatches(f.code) * searchWeightBody
+	case QueryFieldPath:
+		if strings.Contains(f.path, strings.ToLower(c.Value)) {
+			return searchWeightTitle
+		}
+		return 0
+	case QueryFieldTag:
+		if util.StringSliceContains(f.tags, strings.ToLower(strings.TrimPrefix(c.Value, "#"))) {
+			return searchWeightHeading
+		}
+		return 0
+	}
+	return c.countMatches(f.title)*searchWeightTitle +
+		c.countMatches(f.headings)*searchWeightHeading +
+		c.countMatches(f.body)*searchWeightBody
+}
+
+// the number of places the clause terms occur consecutively in the tokens
+func (c QueryClause) countMatches(tokens []token) int {
+	n := 0
+	if len(c.Terms) == 0 {
+		return n
+	}
+	for i := 0; i+len(c.Terms) <= len(tokens); i++ {
+		if c.matchesAt(tokens, i) {
+			n++
+		}
+	}
+	return n
+}
+
+func (c QueryClause) matchesAt(tokens []token, i int) bool {
+	last := len(c.Terms) - 1
+	for j, term := range c.Terms {
+		// phrases don't span lines (i.e. blocks of text)
+		if tokens[i+j].Line != tokens[i].Line {
+			return false
+		}
+		t := tokens[i+j].Text
+		if c.Exact || j < last {
+			if t != term {
+				return false
+			}
+		} else if !strings.HasPrefix(t, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+)
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery(`rebase -title:"merge conflicts" OR code:kubectl path:k8s/ tag:#ops`)
+
+	assert.Equal(t, 2, len(q.Groups))
+	assert.Equal(t, []QueryClause{
+		{Value: "rebase", Terms: []string{"rebase"}},
+		{Negate: true, Field: QueryFieldTitle, Value: "merge conflicts", Terms: []string{"merge", "conflicts"}, Exact: true},
+	}, q.Groups[0])
+	assert.Equal(t, []QueryClause{
+		{Field: QueryFieldCode, Value: "kubectl", Terms: []string{"kubectl"}},
+		{Field: QueryFieldPath, Value: "k8s/", Terms: []string{"k8s"}},
+		{Field: QueryFieldTag, Value: "#ops", Terms: []string{"ops"}},
+	}, q.Groups[1])
+
+	assert.Equal(t, []string{"rebase", "kubectl"}, q.HighlightTerms())
+	assert.True(t, ParseQuery("  OR ").IsEmpty())
+}
+
+func TestSearchQueryLanguage(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+
+	writeNote(t, dir, "git/rebase.md", "# Rebase\n\nresolve merge conflicts with care #git\n")
+	writeNote(t, dir, "git/merge.md", "# Merge\n\n## Conflicts\n\nconflicts merge\n")
+	writeNote(t, dir, "k8s/rollout.md", "# Rollouts\n\n```\nkubectl rollout status\n```\n\nrollout docs #ops\n")
+	writeNote(t, dir, "k8s/pods.md", "# Pods\n\nkubectl is mentioned outside of code\n")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+	paths := func(query string) []string {
+		res := make([]string, 0)
+		results, _ := fm.Search(query)
+		for _, r := range results {
+			res = append(res, r.Path.QueryPath())
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"git/rebase"}, paths(`"merge conflicts"`))
+	assert.Equal(t, []string{"git/merge", "git/rebase"}, paths(`merge conflicts`))
+	assert.Equal(t, []string{"git/merge"}, paths(`conflicts -care`))
+	assert.Equal(t, []string{"git/merge"}, paths(`heading:conflicts`))
+	assert.Equal(t, []string{"git/merge"}, paths(`title:merge`))
+	assert.Equal(t, []string{"k8s/rollout"}, paths(`code:kubectl`))
+	assert.ElementsMatch(t, []string{"k8s/rollout", "k8s/pods"}, paths(`path:k8s kubectl`))
+	assert.ElementsMatch(t, []string{"k8s/rollout", "git/rebase"}, paths(`tag:ops OR tag:#git`))
+	assert.Equal(t, []string{}, paths(`-kubectl`))
+}
@@ -0,0 +1,245 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// relative weight of a term occurring in each part of a note
+const (
+	searchWeightBody    = 1
+	searchWeightHeading = 5
+	searchWeightTitle   = 10
+)
+
+// how much context to show either side of the first match in a snippet
+const snippetRadius = 40
+
+type token struct {
+	Text  string // lower case
+	Range TextRange
+	Line  int
+}
+
+// Tokenise - split text into lower case words, where a word is a run of letters and digits
+func Tokenise(s string) []string {
+	res := make([]string, 0)
+	for _, t := range tokenSpans(s) {
+		res = append(res, t.Text)
+	}
+	return res
+}
+
+func tokenSpans(s string) []token {
+	res := make([]token, 0)
+	start := -1
+	line := 0
+	for i, r := range s {
+		isWordChar := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordChar && start < 0 {
+			start = i
+		} else if !isWordChar && start >= 0 {
+			res = append(res, token{strings.ToLower(s[start:i]), TextRange{start, i}, line})
+			start = -1
+		}
+		if r == '\n' {
+			line++
+		}
+	}
+	if start >= 0 {
+		res = append(res, token{strings.ToLower(s[start:]), TextRange{start, len(s)}, line})
+	}
+	return res
+}
+
+// searchMatcher - a way of searching notes: scores each note and highlights what matched
+type searchMatcher interface {
+	score(e *IndexEntry) (int, bool)
+	highlighter() Highlighter
+}
+
+// ParseSearch - parse a search in any of the modes:
+// `/pattern/` for a regular expression, `~words` for fuzzy matching, otherwise the query language (see ParseQuery)
+func ParseSearch(query string) (searchMatcher, error) {
+	trimmed := strings.TrimSpace(query)
+	if _, _, ok := splitRegexQuery(trimmed); ok {
+		return parseRegexSearch(trimmed)
+	}
+	if strings.HasPrefix(trimmed, "~") {
+		return makeFuzzySearch(trimmed[1:]), nil
+	}
+	return ParseQuery(query), nil
+}
+
+// Search - find notes matching the query (see ParseSearch), highest scoring first
+func (fm *FileManager) Search(query string) ([]*SearchResultItem, error) {
+	res := make([]*SearchResultItem, 0)
+	m, err := ParseSearch(query)
+	if err != nil {
+		return res, err
+	}
+
+	for _, e := range fm.IndexEntries() {
+		if item := scoreEntry(e, m); item != nil {
+			res = append(res, item)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+	return res, nil
+}
+
+func scoreEntry(e *IndexEntry, m searchMatcher) *SearchResultItem {
+	score, ok := m.score(e)
+	if !ok {
+		return nil
+	}
+
+	highlight := m.highlighter()
+	sections := []sectionText{{nil, e.Text}}
+	if e.File.Document != nil {
+		sections = sectionTexts(e.File.Document)
+	}
+	loc := locateMatch(sections, highlight)
+
+	item := SearchResultItem{
+		File:      e.File,
+		Path:      e.Path,
+		Score:     score,
+		Highlight: highlight,
+		Document:  loc.Document,
+		Line:      loc.Line,
+		Match:     loc.Before,
+	}
+	if loc.Document != nil {
+		item.HeadingPath = loc.Document.HeadingPath()
+	}
+	item.Snippet, item.SnippetMatches = makeSnippet(loc.Text, loc.Matches)
+	return &item
+}
+
+// the headings of all sub documents, depth first
+func documentHeadings(doc *Document) []string {
+	res := make([]string, 0)
+	if doc == nil {
+		return res
+	}
+	for _, sub := range doc.SubDocuments {
+		res = append(res, sub.SearchTerm)
+		res = append(res, documentHeadings(sub)...)
+	}
+	return res
+}
+
+// sectionText - the text belonging directly to a section, i.e. excluding its sub sections
+type sectionText struct {
+	Document *Document
+	Text     string
+}
+
+// sectionTexts - the text of each section of the document in document order.
+// Joined together, these are the plain text of the whole document
+func sectionTexts(doc *Document) []sectionText {
+	var sb strings.Builder
+	for _, n := range doc.OwnContent() {
+		sb.WriteString(util.PlainText(n))
+	}
+
+	res := []sectionText{{doc, sb.String()}}
+	for _, sub := range doc.SubDocuments {
+		res = append(res, sectionTexts(sub)...)
+	}
+	return res
+}
+
+// matchLocation - the line with the most matches: its section, line number, text and matches,
+// and the number of matches before it
+type matchLocation struct {
+	Document *Document
+	Line     int
+	Text     string
+	Matches  []TextRange
+	Before   int
+}
+
+// locateMatch - find the line with the most matches, and the section and line number it is in
+func locateMatch(sections []sectionText, highlight Highlighter) matchLocation {
+	var best matchLocation
+	lineOffset, count := 0, 0
+	for _, section := range sections {
+		for i, line := range strings.Split(section.Text, "\n") {
+			matches := highlight(line)
+			if len(matches) > len(best.Matches) {
+				best = matchLocation{section.Document, lineOffset + i, line, matches, count}
+			}
+			count += len(matches)
+		}
+		lineOffset += strings.Count(section.Text, "\n")
+	}
+	return best
+}
+
+// makeSnippet - trim a line to the context around its first match, adjusting the match ranges to suit
+func makeSnippet(line string, matches []TextRange) (string, []TextRange) {
+	if len(matches) == 0 {
+		return "", []TextRange{}
+	}
+
+	// window the line around the first match
+	start := matches[0].Start - snippetRadius
+	end := matches[0].End + snippetRadius
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(line) {
+		end, suffix = len(line), ""
+	}
+	// keep to rune boundaries
+	for start > 0 && !utf8.RuneStart(line[start]) {
+		start--
+	}
+	for end < len(line) && !utf8.RuneStart(line[end]) {
+		end++
+	}
+
+	offset := len(prefix) - start
+	snippet := prefix + line[start:end] + suffix
+	ranges := make([]TextRange, 0)
+	for _, m := range matches {
+		if m.Start >= start && m.End <= end {
+			ranges = append(ranges, TextRange{m.Start + offset, m.End + offset})
+		}
+	}
+	return snippet, ranges
+}
+
+// MatchRanges - the ranges of the words in s matching any of the (lower case) terms
+func MatchRanges(s string, terms []string) []TextRange {
+	return matchRanges(s, terms)
+}
+
+// TermHighlighter - highlights words starting with any of the (lower case) terms
+func TermHighlighter(terms []string) Highlighter {
+	return func(s string) []TextRange {
+		return matchRanges(s, terms)
+	}
+}
+
+func matchRanges(s string, terms []string) []TextRange {
+	res := make([]TextRange, 0)
+	for _, t := range tokenSpans(s) {
+		for _, term := range terms {
+			if strings.HasPrefix(t.Text, term) {
+				res = append(res, t.Range)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// regexSearch - notes matching a regular expression, line by line
+type regexSearch struct {
+	pattern *regexp.Regexp
+}
+
+// regexFlags - the flags that can follow a regular expression: i to ignore case
+const regexFlags = "i"
+
+// splitRegexQuery - split `/pattern/flags` into the pattern and flags. False if the text isn't a regular expression,
+// e.g. a path such as /usr/local/bin, where what follows the last slash isn't flags
+func splitRegexQuery(s string) (string, string, bool) {
+	end := strings.LastIndex(s, "/")
+	if !strings.HasPrefix(s, "/") || end <= 0 {
+		return "", "", false
+	}
+	flags := s[end+1:]
+	if strings.Trim(flags, regexFlags) != "" {
+		return "", "", false
+	}
+	return s[1:end], flags, true
+}
+
+// parse `/pattern/flags`. Matching is case insensitive if the `i` flag is given,
+// or if there are no flags and the pattern is all lower case
+func parseRegexSearch(s string) (searchMatcher, error) {
+	pattern, flags, ok := splitRegexQuery(s)
+	if !ok {
+		return nil, util.Invalid("%s is not a regular expression", s)
+	}
+	if pattern == "" {
+		return nil, util.Invalid("empty search pattern")
+	}
+
+	insensitive := strings.Contains(flags, "i")
+	if flags == "" && !hasUpperCaseLiteral(pattern) {
+		insensitive = true
+	}
+	if insensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, &util.Error{Kind: util.ErrorInvalid, Reason: "invalid search pattern", Cause: err}
+	}
+	return regexSearch{re}, nil
+}
+
+// hasUpperCaseLiteral - does the pattern match an upper case letter itself, i.e. not counting escapes such as \S or \p{Lu}
+func hasUpperCaseLiteral(pattern string) bool {
+	rs := []rune(pattern)
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '\\' {
+			i++
+			if i < len(rs) && (rs[i] == 'p' || rs[i] == 'P') && i+1 < len(rs) && rs[i+1] == '{' {
+				for i < len(rs) && rs[i] != '}' {
+					i++
+				}
+			}
+			continue
+		}
+		if unicode.IsUpper(rs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs regexSearch) countMatches(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		n += len(rs.pattern.FindAllStringIndex(line, -1))
+	}
+	return n
+}
+
+func (rs regexSearch) score(e *IndexEntry) (int, bool) {
+	titles := []string{e.Path.QueryPath()}
+	headings := []string{}
+	if doc := e.File.Document; doc != nil {
+		titles = append(titles, doc.SearchTerm)
+		headings = documentHeadings(doc)
+	}
+	score := rs.countMatches(titles)*searchWeightTitle +
+		rs.countMatches(headings)*searchWeightHeading +
+		rs.countMatches(strings.Split(e.Text, "\n"))*searchWeightBody
+	return score, score > 0
+}
+
+func (rs regexSearch) highlighter() Highlighter {
+	return func(s string) []TextRange {
+		res := make([]TextRange, 0)
+		for _, m := range rs.pattern.FindAllStringIndex(s, -1) {
+			if m[1] > m[0] {
+				res = append(res, TextRange{m[0], m[1]})
+			}
+		}
+		return res
+	}
+}
+
+// fuzzySearch - notes containing words close to every term, to allow for typos
+type fuzzySearch struct {
+	words []string
+}
+
+func makeFuzzySearch(s string) fuzzySearch {
+	return fuzzySearch{Tokenise(s)}
+}
+
+// the number of edits allowed scales with the length of the term
+func fuzzyMaxEdits(term string) int {
+	l := len([]rune(term))
+	switch {
+	case l <= 2:
+		return 0
+	case l <= 5:
+		return 1
+	}
+	return 2
+}
+
+// fuzzyScore - how closely a token matches the term: 0 if not at all, higher for fewer edits
+func fuzzyScore(tok, term string) int {
+	maxEdits := fuzzyMaxEdits(term)
+	diff := len([]rune(tok)) - len([]rune(term))
+	if diff > maxEdits || -diff > maxEdits {
+		return 0
+	}
+	d := util.EditDistance(tok, term)
+	if d > maxEdits {
+		return 0
+	}
+	return maxEdits + 1 - d
+}
+
+func fuzzyFieldScore(tokens []token, term string) int {
+	n := 0
+	for _, t := range tokens {
+		n += fuzzyScore(t.Text, term)
+	}
+	return n
+}
+
+func (fs fuzzySearch) score(e *IndexEntry) (int, bool) {
+	if len(fs.words) == 0 {
+		return 0, false
+	}
+	f := e.searchFields()
+	score := 0
+	for _, term := range fs.words {
+		termScore := fuzzyFieldScore(f.title, term)*searchWeightTitle +
+			fuzzyFieldScore(f.headings, term)*searchWeightHeading +
+			fuzzyFieldScore(f.body, term)*searchWeightBody
+		if termScore == 0 {
+			return 0, false
+		}
+		score += termScore
+	}
+	return score, true
+}
+
+func (fs fuzzySearch) highlighter() Highlighter {
+	return func(s string) []TextRange {
+		res := make([]TextRange, 0)
+		for _, t := range tokenSpans(s) {
+			for _, term := range fs.words {
+				if fuzzyScore(t.Text, term) > 0 {
+					res = append(res, t.Range)
+					break
+				}
+			}
+		}
+		return res
+	}
+}
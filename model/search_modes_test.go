@@ -0,0 +1,94 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+)
+
+func TestParseSearchModes(t *testing.T) {
+	m, err := ParseSearch("/kubectl.*rollout/")
+	assert.Nil(t, err)
+	assert.IsType(t, regexSearch{}, m)
+
+	m, _ = ParseSearch("~kubctl")
+	assert.Equal(t, fuzzySearch{[]string{"kubctl"}}, m)
+
+	// a lone slash is a plain query
+	m, _ = ParseSearch("/tmp")
+	assert.IsType(t, Query{}, m)
+
+	// as is a path, or anything else where what follows the last slash isn't flags
+	m, err = ParseSearch("/usr/local/bin")
+	assert.Nil(t, err)
+	assert.IsType(t, Query{}, m)
+	m, err = ParseSearch("/x/q")
+	assert.Nil(t, err)
+	assert.IsType(t, Query{}, m)
+	m, _ = ParseSearch("/x/ii")
+	assert.IsType(t, regexSearch{}, m)
+
+	_, err = ParseSearch("/kubectl(/")
+	assert.NotNil(t, err)
+}
+
+func TestRegexSearchCase(t *testing.T) {
+	highlight := func(query, s string) []TextRange {
+		m, _ := ParseSearch(query)
+		return m.highlighter()(s)
+	}
+
+	assert.Equal(t, []TextRange{{0, 4}}, highlight("/head/", "HEAD~3"))
+	assert.Equal(t, []TextRange{}, highlight("/Head/", "HEAD~3"))
+	assert.Equal(t, []TextRange{{0, 4}}, highlight("/Head/i", "HEAD~3"))
+	// escapes aren't upper case letters
+	assert.Equal(t, []TextRange{{0, 17}}, highlight(`/kubectl\S+rollout/`, "KUBECTL---ROLLOUT more"))
+	assert.False(t, hasUpperCaseLiteral(`\W\D\B\p{Lu}x`))
+	assert.True(t, hasUpperCaseLiteral(`\p{Lu}X`))
+}
+
+func TestFuzzyScore(t *testing.T) {
+	assert.Equal(t, 3, fuzzyScore("kubernetes", "kubernetes"))
+	assert.Equal(t, 2, fuzzyScore("kubernetes", "kuberentes"))
+	assert.Equal(t, 1, fuzzyScore("kubernetes", "kubrentes"))
+	assert.Equal(t, 0, fuzzyScore("kubernetes", "kube"))
+	// short terms must match exactly
+	assert.Equal(t, 0, fuzzyScore("go", "do"))
+}
+
+func TestRegexAndFuzzySearch(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+
+	writeNote(t, dir, "k8s/deploy.md", "# Deploy\n\n## Rollback\n\n```\nkubectl rollout undo deploy/web\n```\n")
+	writeNote(t, dir, "k8s/pods.md", "# Pods\n\nkubectl get pods\n")
+	writeNote(t, dir, "git/rebase.md", "# Rebase\n\ngit rebase -i HEAD~3\n")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+
+	results, err := fm.Search("/kubectl.*rollout/")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "k8s/deploy", results[0].Path.QueryPath())
+	assert.Equal(t, []string{"Rollback"}, results[0].HeadingPath)
+	assert.Equal(t, "kubectl rollout undo deploy/web", results[0].Snippet)
+	assert.Equal(t, []TextRange{{0, 15}}, results[0].SnippetMatches)
+
+	results, _ = fm.Search("~kubeclt")
+	assert.Equal(t, 2, len(results))
+	results, _ = fm.Search("~kubeclt rolout")
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "k8s/deploy", results[0].Path.QueryPath())
+	assert.Equal(t, []TextRange{{0, 7}, {8, 15}}, results[0].SnippetMatches)
+
+	// matches in the title rank highest
+	results, _ = fm.Search("~rebsae")
+	assert.Equal(t, "git/rebase", results[0].Path.QueryPath())
+
+	results, err = fm.Search("/[/")
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(results))
+}
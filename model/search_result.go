@@ -1,8 +1,26 @@
 package model
 
+// TextRange - a range of byte offsets within a string, end exclusive
+type TextRange struct {
+	Start int
+	End   int
+}
+
+// Highlighter - finds the ranges of a string to highlight as matching a search
+type Highlighter func(string) []TextRange
+
 // SearchResultItem ...
 type SearchResultItem struct {
-	File  *File
-	Path  FilePath
-	Score int
+	File           *File
+	Path           FilePath
+	Score          int
+	Highlight      Highlighter
+	Snippet        string
+	SnippetMatches []TextRange
+	// where the best match is: the section, its heading path and the line within the plain text of the file
+	Document    *Document
+	HeadingPath []string
+	Line        int
+	// Match - how many matches of the highlighter come before the best match, in document order
+	Match int
 }
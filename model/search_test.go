@@ -0,0 +1,71 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+)
+
+func TestTokenise(t *testing.T) {
+	assert.Equal(t, []string{"git", "rebase", "i", "head", "3"}, Tokenise("Git rebase -i HEAD~3"))
+	assert.Equal(t, []string{}, Tokenise("  --  "))
+}
+
+func TestMakeSnippet(t *testing.T) {
+	line := "run the Rebase command to rebase things"
+	snippet, matches := makeSnippet(line, MatchRanges(line, []string{"rebase"}))
+
+	assert.Equal(t, "run the Rebase command to rebase things", snippet)
+	assert.Equal(t, []TextRange{{8, 14}, {26, 32}}, matches)
+	assert.Equal(t, "Rebase", snippet[matches[0].Start:matches[0].End])
+
+	long := strings.Repeat("a ", 50) + "rebase" + strings.Repeat(" b", 50)
+	snippet, matches = makeSnippet(long, MatchRanges(long, []string{"rebase"}))
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Equal(t, "rebase", snippet[matches[0].Start:matches[0].End])
+}
+
+func TestSearchRanksTitleThenHeadingThenBody(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+
+	writeNote(t, dir, "body.md", "# Body\n\nsquash squash squash\n")
+	writeNote(t, dir, "heading.md", "# Heading\n\n## Squash\n\ntext\n")
+	writeNote(t, dir, "squash.md", "# Squash commits\n\ntext\n")
+	writeNote(t, dir, "other.md", "# Other\n\nnothing\n")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+	results, err := fm.Search("SQUASH")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "squash", results[0].Path.QueryPath())
+	assert.Equal(t, "heading", results[1].Path.QueryPath())
+	assert.Equal(t, "body", results[2].Path.QueryPath())
+	assert.Equal(t, "squash squash squash", results[2].Snippet)
+	assert.Equal(t, []string{"Squash"}, results[1].HeadingPath)
+	assert.Equal(t, "Squash", results[1].Document.SearchTerm)
+	assert.Equal(t, 1, results[1].Line)
+
+	// every term has to match
+	results, _ = fm.Search("squash text")
+	assert.Equal(t, 2, len(results))
+	results, _ = fm.Search("squash missing")
+	assert.Equal(t, 0, len(results))
+}
+
+func TestLocateMatch(t *testing.T) {
+	sections := []sectionText{{nil, "Intro\none squash\n"}, {nil, "squash and squash\nend"}}
+	loc := locateMatch(sections, TermHighlighter([]string{"squash"}))
+
+	assert.Equal(t, 2, loc.Line)
+	assert.Equal(t, "squash and squash", loc.Text)
+	assert.Equal(t, 2, len(loc.Matches))
+	// the match on the line before
+	assert.Equal(t, 1, loc.Before)
+}
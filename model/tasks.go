@@ -0,0 +1,115 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/thomgray/notebee/util"
+	"golang.org/x/net/html"
+)
+
+// TaskItem - a GitHub-flavoured task list item, `- [ ] todo` or `- [x] done`
+type TaskItem struct {
+	Path FilePath
+	// Document - the section the task is in
+	Document    *Document
+	HeadingPath []string
+	Text        string
+	Done        bool
+}
+
+var taskMarkerRegex = regexp.MustCompile(`^\s*\[([ xX])\](\s+|$)`)
+
+// TaskMarker - if the list item is a task, the text node starting with its `[ ]` marker and whether it is done
+func TaskMarker(li *html.Node) (*html.Node, bool, bool) {
+	if li == nil || li.Type != html.ElementNode || li.Data != "li" {
+		return nil, false, false
+	}
+	// the marker starts the first text, which may be in a paragraph for loose lists
+	for c := li.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode && strings.TrimSpace(c.Data) == "":
+			continue
+		case c.Type == html.ElementNode && c.Data == "p":
+			c = c.FirstChild
+			if c == nil || c.Type != html.TextNode {
+				return nil, false, false
+			}
+		}
+		if c.Type != html.TextNode {
+			return nil, false, false
+		}
+		m := taskMarkerRegex.FindStringSubmatch(c.Data)
+		if m == nil {
+			return nil, false, false
+		}
+		return c, m[1] != " ", true
+	}
+	return nil, false, false
+}
+
+// StripTaskMarker - the text of a task item without the `[ ]` marker
+func StripTaskMarker(s string) string {
+	return taskMarkerRegex.ReplaceAllString(s, "")
+}
+
+// Tasks - the task items in all notes, in document order
+func (fm *FileManager) Tasks() []TaskItem {
+	res := make([]TaskItem, 0)
+	for _, e := range fm.IndexEntries() {
+		if e.File.Document != nil {
+			res = append(res, documentTasks(e.Path, e.File.Document)...)
+		}
+	}
+	return res
+}
+
+// OpenTasks - the task items not yet done in all notes
+func (fm *FileManager) OpenTasks() []TaskItem {
+	res := make([]TaskItem, 0)
+	for _, t := range fm.Tasks() {
+		if !t.Done {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func documentTasks(path FilePath, doc *Document) []TaskItem {
+	res := make([]TaskItem, 0)
+	headingPath := doc.HeadingPath()
+	var f func(n *html.Node)
+	f = func(n *html.Node) {
+		if _, done, ok := TaskMarker(n); ok {
+			res = append(res, TaskItem{
+				Path:        path,
+				Document:    doc,
+				HeadingPath: headingPath,
+				Text:        taskText(n),
+				Done:        done,
+			})
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	for _, n := range doc.OwnContent() {
+		f(n)
+	}
+	for _, sub := range doc.SubDocuments {
+		res = append(res, documentTasks(path, sub)...)
+	}
+	return res
+}
+
+// the text of the item itself, without any nested lists
+func taskText(li *html.Node) string {
+	var sb strings.Builder
+	for c := li.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (c.Data == "ul" || c.Data == "ol") {
+			continue
+		}
+		sb.WriteString(util.PlainText(c))
+	}
+	return strings.Join(strings.Fields(StripTaskMarker(sb.String())), " ")
+}
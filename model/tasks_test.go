@@ -0,0 +1,61 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+	"golang.org/x/net/html"
+)
+
+func TestTaskMarker(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<ul><li>[ ] open</li><li><p>[x] done</p></li><li>[link] not a task</li><li><em>[ ] not either</em></li></ul>"))
+	var items []*html.Node
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "li" {
+			items = append(items, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(node)
+
+	marker, done, ok := TaskMarker(items[0])
+	assert.True(t, ok)
+	assert.False(t, done)
+	assert.Equal(t, "[ ] open", marker.Data)
+	assert.Equal(t, "open", StripTaskMarker(marker.Data))
+
+	_, done, ok = TaskMarker(items[1])
+	assert.True(t, ok)
+	assert.True(t, done)
+
+	_, _, ok = TaskMarker(items[2])
+	assert.False(t, ok)
+	_, _, ok = TaskMarker(items[3])
+	assert.False(t, ok)
+}
+
+func TestOpenTasks(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	writeNote(t, dir, "release.md", "# Release\n\n- [ ] tag the **build**\n- [x] write notes\n\n## Deploy\n\n- [ ] roll out\n    - [ ] canary first\n- not a task\n")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+	assert.Equal(t, 4, len(fm.Tasks()))
+
+	open := fm.OpenTasks()
+	assert.Equal(t, 3, len(open))
+	assert.Equal(t, "tag the build", open[0].Text)
+	assert.Equal(t, []string{}, open[0].HeadingPath)
+	assert.Equal(t, "roll out", open[1].Text)
+	assert.Equal(t, []string{"Deploy"}, open[1].HeadingPath)
+	assert.Equal(t, "Deploy", open[1].Document.SearchTerm)
+	assert.Equal(t, "canary first", open[2].Text)
+	assert.Equal(t, "release", open[2].Path.QueryPath())
+}
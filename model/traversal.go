@@ -0,0 +1,215 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thomgray/notebee/util"
+)
+
+// TraversalScope - where a traversal query starts from
+type TraversalScope uint8
+
+const (
+	// TraversalScopeDefault - current location, falling back to external
+	TraversalScopeDefault TraversalScope = iota
+	// TraversalScopeExternal - top level of all documents (`*`)
+	TraversalScopeExternal
+	// TraversalScopeRoot - root of the current document (`/`)
+	TraversalScopeRoot
+	// TraversalScopeCurrent - current location in the current document (`.`)
+	TraversalScopeCurrent
+)
+
+// ParseTraversalScope - split the scope prefix from a traversal query.
+// Returns the scope, the prefix as typed (including trailing whitespace) and the remaining query
+func ParseTraversalScope(query string) (TraversalScope, string, string) {
+	trimmed := strings.TrimLeft(query, " ")
+	if trimmed == "" {
+		return TraversalScopeDefault, "", trimmed
+	}
+	var scope TraversalScope
+	switch trimmed[0] {
+	case '*':
+		scope = TraversalScopeExternal
+	case '/':
+		scope = TraversalScopeRoot
+	case '.':
+		scope = TraversalScopeCurrent
+	default:
+		return TraversalScopeDefault, "", trimmed
+	}
+	rest := strings.TrimLeft(trimmed[1:], " ")
+	prefix := trimmed[:len(trimmed)-len(rest)]
+	return scope, prefix, rest
+}
+
+// Traverse - resolve a traversal query (including any scope prefix) to a location.
+// A root or current scope with nothing after it is the top of the note or the current section.
+// The current location is updated on success. If nothing matches, the error says why
+func (fm *FileManager) Traverse(query string) (*Location, error) {
+	scope, _, rest := ParseTraversalScope(query)
+	words := util.StringSplitFlat(rest)
+	if len(words) == 0 {
+		switch scope {
+		case TraversalScopeRoot:
+			return fm.setDocument(fm.rootDocument())
+		case TraversalScopeCurrent:
+			return fm.setDocument(fm.currentDocument())
+		}
+		return nil, util.Invalid("nothing to open")
+	}
+
+	var loc *Location
+	var err error
+	switch scope {
+	case TraversalScopeExternal:
+		loc, err = fm.traverseExternal(words)
+	case TraversalScopeRoot:
+		loc, err = fm.traverseFrom(fm.rootDocument(), words)
+	case TraversalScopeCurrent:
+		loc, err = fm.traverseFrom(fm.currentDocument(), words)
+	default:
+		loc, err = fm.traverseFrom(fm.currentDocument(), words)
+		if loc == nil {
+			loc, err = fm.traverseExternal(words)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	fm.SetLocation(loc)
+	return loc, nil
+}
+
+func (fm *FileManager) currentDocument() *Document {
+	if fm.CurrentLocation == nil {
+		return nil
+	}
+	return fm.CurrentLocation.Document
+}
+
+func (fm *FileManager) rootDocument() *Document {
+	if fm.CurrentLocation == nil || fm.CurrentLocation.File == nil {
+		return nil
+	}
+	return fm.CurrentLocation.File.Document
+}
+
+// setDocument - move to another document of the current file
+func (fm *FileManager) setDocument(doc *Document) (*Location, error) {
+	if doc == nil {
+		return nil, util.Invalid("no note is open")
+	}
+	loc := *fm.CurrentLocation
+	loc.Document = doc
+	fm.SetLocation(&loc)
+	return &loc, nil
+}
+
+func (fm *FileManager) traverseFrom(doc *Document, words []string) (*Location, error) {
+	if doc == nil {
+		return nil, util.Invalid("no note is open")
+	}
+	found := doc.Traverse(words)
+	if found == nil {
+		return nil, util.NotFound("no heading in %s matches '%s'", doc.SearchTerm, strings.Join(words, " "))
+	}
+	loc := *fm.CurrentLocation
+	loc.Document = found
+	return &loc, nil
+}
+
+// the first word is the path to the file, any remaining words traverse the headings within it
+func (fm *FileManager) traverseExternal(words []string) (*Location, error) {
+	headings := words[1:]
+	candidates, path := fm.FindSupportedFilePathsForQuery(words[0])
+
+	// why the last note with the path didn't match
+	err := error(util.NotFound("no note matches '%s'", words[0]))
+	for _, p := range candidates {
+		qp := p.QueryPath()
+		if !strings.EqualFold(qp, path) {
+			continue
+		}
+		f, loadErr := fm.LoadFile(p)
+		if loadErr != nil || f == nil || f.Document == nil {
+			if loadErr != nil {
+				err = loadErr
+			}
+			continue
+		}
+		if doc := f.Document.Traverse(headings); doc != nil {
+			return makeLocation(p, f, doc), nil
+		}
+		err = util.NotFound("no heading in %s matches '%s'", qp, strings.Join(headings, " "))
+	}
+	return nil, err
+}
+
+func makeLocation(p FilePath, f *File, doc *Document) *Location {
+	qp := p.QueryPath()
+	return &Location{
+		BaseDir:              p.BaseDir,
+		RelativePath:         strings.Split(filepath.Dir(qp), string(os.PathSeparator)),
+		RelativePathWithName: qp,
+		File:                 f,
+		Document:             doc,
+	}
+}
+
+// SuggestHeadings - suggest heading completions within the scope of the query.
+// Suggestions include the scope prefix as typed. External scope suggests from the note named by the first word
+// once it is followed by a space, and default scope falls back to this if the current location has no suggestions
+func (fm *FileManager) SuggestHeadings(query string) []AutocompleteResult {
+	scope, prefix, rest := ParseTraversalScope(query)
+	switch scope {
+	case TraversalScopeRoot:
+		return headingCompletions(fm.rootDocument(), prefix, rest)
+	case TraversalScopeCurrent:
+		return headingCompletions(fm.currentDocument(), prefix, rest)
+	case TraversalScopeExternal:
+		return fm.suggestFileHeadings(prefix, rest)
+	}
+	res := headingCompletions(fm.currentDocument(), prefix, rest)
+	if len(res) > 0 {
+		return res
+	}
+	return fm.suggestFileHeadings(prefix, rest)
+}
+
+func headingCompletions(doc *Document, prefix, typed string) []AutocompleteResult {
+	res := make([]AutocompleteResult, 0)
+	if doc == nil {
+		return res
+	}
+	for _, s := range doc.CompleteSubQuery(typed) {
+		res = append(res, AutocompleteResult{
+			Str:     prefix + s,
+			Kind:    AutocompleteKindHeading,
+			Heading: s,
+		})
+	}
+	return res
+}
+
+// heading completions for `path heading...`, where path is a note
+func (fm *FileManager) suggestFileHeadings(prefix, rest string) []AutocompleteResult {
+	i := strings.IndexAny(rest, " \t")
+	if i < 0 {
+		return []AutocompleteResult{}
+	}
+	path := rest[:i]
+	candidates, qp := fm.FindSupportedFilePathsForQuery(path)
+	for _, p := range candidates {
+		if !strings.EqualFold(p.QueryPath(), qp) {
+			continue
+		}
+		if f, _ := fm.LoadFile(p); f != nil && f.Document != nil {
+			return headingCompletions(f.Document, prefix+path+" ", strings.TrimLeft(rest[i:], " \t"))
+		}
+	}
+	return []AutocompleteResult{}
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+	"github.com/thomgray/notebee/util"
+	"golang.org/x/net/html"
+)
+
+func TestParseTraversalScope(t *testing.T) {
+	scope, prefix, rest := ParseTraversalScope("* git/rebase")
+	assert.Equal(t, TraversalScopeExternal, scope)
+	assert.Equal(t, "* ", prefix)
+	assert.Equal(t, "git/rebase", rest)
+
+	scope, prefix, rest = ParseTraversalScope("/interactive")
+	assert.Equal(t, TraversalScopeRoot, scope)
+	assert.Equal(t, "/", prefix)
+	assert.Equal(t, "interactive", rest)
+
+	scope, _, rest = ParseTraversalScope(". squash")
+	assert.Equal(t, TraversalScopeCurrent, scope)
+	assert.Equal(t, "squash", rest)
+
+	scope, prefix, rest = ParseTraversalScope("git/rebase")
+	assert.Equal(t, TraversalScopeDefault, scope)
+	assert.Equal(t, "", prefix)
+	assert.Equal(t, "git/rebase", rest)
+}
+
+func TestTraverseFromLocation(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h1>Rebase</h1><h2>Interactive</h2><h3>Squash</h3><h2>Onto</h2><h3>Squash</h3></body>"))
+	doc := DocumentFromNode(node, "rebase")
+	f := &File{Document: doc}
+
+	fm := FileManager{}
+	fm.SetLocation(&Location{File: f, Document: doc.Traverse([]string{"onto"})})
+
+	loc, err := fm.Traverse(". squash")
+	assert.NoError(t, err)
+	assert.NotNil(t, loc)
+	assert.Equal(t, "Onto", loc.Document.Super.SearchTerm)
+
+	loc, err = fm.Traverse("/ interactive squash")
+	assert.NoError(t, err)
+	assert.NotNil(t, loc)
+	assert.Equal(t, "Interactive", loc.Document.Super.SearchTerm)
+	assert.Equal(t, loc, fm.CurrentLocation)
+
+	loc, err = fm.Traverse(". onto")
+	assert.Nil(t, loc)
+	assert.True(t, util.IsErrorKind(err, util.ErrorNotFound))
+	assert.Equal(t, "no heading in Squash matches 'onto'", err.Error())
+
+	// a scope on its own is the top of the note, or the current section
+	loc, err = fm.Traverse(". ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Squash", loc.Document.SearchTerm)
+	loc, err = fm.Traverse("/")
+	assert.NoError(t, err)
+	assert.Equal(t, doc, loc.Document)
+	assert.Equal(t, loc, fm.CurrentLocation)
+
+	_, err = fm.Traverse("*")
+	assert.True(t, util.IsErrorKind(err, util.ErrorInvalid))
+	_, err = (&FileManager{}).Traverse("/")
+	assert.Equal(t, "no note is open", err.Error())
+}
+
+func TestCompleteSubQuery(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h1>Rebase</h1><h2>Interactive mode</h2><h3>Squash</h3><h3>Fixup</h3><h2>Onto</h2></body>"))
+	doc := DocumentFromNode(node, "rebase")
+
+	assert.Equal(t, []string{"Interactive mode", "Onto"}, doc.CompleteSubQuery(""))
+	assert.Equal(t, []string{"Interactive mode"}, doc.CompleteSubQuery("inter"))
+	assert.Equal(t, []string{"Interactive mode Squash", "Interactive mode Fixup"}, doc.CompleteSubQuery("interactive mode "))
+	assert.Equal(t, []string{"Interactive mode Fixup"}, doc.CompleteSubQuery("interactive mode f"))
+}
+
+func TestSuggestHeadingsOfNote(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	writeNote(t, dir, "docker/networking.md", "# Networking\n\n## Bridge mode\n\n### Ports\n\n## Host mode\n")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+	headings := func(query string) []string {
+		res := make([]string, 0)
+		for _, r := range fm.SuggestHeadings(query) {
+			assert.Equal(t, AutocompleteKindHeading, r.Kind)
+			assert.True(t, strings.HasSuffix(r.Str, r.Heading))
+			res = append(res, r.Str)
+		}
+		return res
+	}
+
+	// still typing the note
+	assert.Equal(t, []string{}, headings("docker/networking"))
+	assert.Equal(t, []string{"docker/networking Bridge mode", "docker/networking Host mode"}, headings("docker/networking "))
+	assert.Equal(t, []string{"* docker/networking Bridge mode Ports"}, headings("* docker/networking bridge mode "))
+	assert.Equal(t, []string{}, headings("docker/missing "))
+	// root scope is the current note only
+	assert.Equal(t, []string{}, headings("/ docker/networking "))
+}
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// WatchPollInterval - how often the polling watcher re-scans the search roots
+	WatchPollInterval = 2 * time.Second
+	// watchSettleDelay - editors often write a file in several steps, so wait for events to settle before refreshing
+	watchSettleDelay = 100 * time.Millisecond
+)
+
+// notifier - a source of filesystem change notifications for a set of directories
+type notifier interface {
+	Add(dir string) error
+	Events() <-chan bool
+	Close()
+}
+
+// Watcher - keeps the index of a file manager up to date with the filesystem.
+// Uses filesystem notifications where the platform supports it, otherwise polls
+type Watcher struct {
+	fm       *FileManager
+	notifier notifier
+	lock     sync.Locker
+	onChange func(IndexChanges)
+	done     chan bool
+}
+
+// Watch - start watching the search roots. onChange is called (from the watcher goroutine) whenever
+// a refresh of the index finds added, changed or removed notes. The lock is held while refreshing,
+// as the refresh reads the config's roots
+func (fm *FileManager) Watch(lock sync.Locker, onChange func(IndexChanges)) *Watcher {
+	w := Watcher{
+		fm:       fm,
+		lock:     lock,
+		onChange: onChange,
+		done:     make(chan bool),
+	}
+	if n, err := newNotifier(); err == nil {
+		w.notifier = n
+		w.addWatches()
+	} else {
+		log.Printf("Filesystem notifications unavailable, polling instead: %v", err)
+	}
+	go w.run()
+	return &w
+}
+
+// Stop - stop watching
+func (w *Watcher) Stop() {
+	close(w.done)
+	if w.notifier != nil {
+		w.notifier.Close()
+	}
+}
+
+func (w *Watcher) run() {
+	var events <-chan bool
+	var ticker *time.Ticker
+	if w.notifier != nil {
+		events = w.notifier.Events()
+		// still poll occasionally, to pick up search roots added since watching started
+		ticker = time.NewTicker(WatchPollInterval * 5)
+	} else {
+		ticker = time.NewTicker(WatchPollInterval)
+	}
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-events:
+			w.settle(events)
+			w.refresh()
+		case <-ticker.C:
+			w.refresh()
+		}
+	}
+}
+
+func (w *Watcher) settle(events <-chan bool) {
+	timer := time.NewTimer(watchSettleDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-events:
+			timer.Reset(watchSettleDelay)
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+func (w *Watcher) refresh() {
+	w.lock.Lock()
+	changes := w.fm.Reload()
+	if w.notifier != nil {
+		w.addWatches()
+	}
+	w.lock.Unlock()
+	if !changes.IsEmpty() && w.onChange != nil {
+		w.onChange(changes)
+	}
+}
+
+// notifications are per directory, so every directory under the roots needs watching.
+// Adding a watch that already exists is harmless
+func (w *Watcher) addWatches() {
+	for _, root := range w.fm.Roots() {
+		for _, dir := range findWatchDirs(root.Path) {
+			if err := w.notifier.Add(dir); err != nil {
+				log.Printf("Unable to watch %s: %v", dir, err)
+			}
+		}
+	}
+}
+
+func findWatchDirs(root string) []string {
+	res := make([]string, 0)
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info == nil || !info.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		res = append(res, path)
+		return nil
+	})
+	return res
+}
+
+// RefreshLocation - re-resolve the current location against the index, e.g. after its file changed.
+// Stays in the same section if it still exists, otherwise moves to the top of the file.
+// Returns nil if the file is no longer indexed
+func (fm *FileManager) RefreshLocation() *Location {
+	loc := fm.CurrentLocation
+	if loc == nil || loc.File == nil {
+		return nil
+	}
+	e := fm.Index.Get(loc.File.Path)
+	if e == nil || e.File.Document == nil {
+		return nil
+	}
+
+	refreshed := *loc
+	refreshed.File = e.File
+	refreshed.Document = e.File.Document
+	if loc.Document != nil {
+		if doc := e.File.Document.Traverse(loc.Document.HeadingPath()); doc != nil {
+			refreshed.Document = doc
+		}
+	}
+	fm.SetLocation(&refreshed)
+	return &refreshed
+}
@@ -0,0 +1,74 @@
+//go:build linux
+// +build linux
+
+package model
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// inotifyNotifier - notifications via inotify. Individual events aren't decoded,
+// any event just prompts the watcher to refresh the index
+type inotifyNotifier struct {
+	fd     int
+	events chan bool
+	done   chan bool
+}
+
+func newNotifier() (notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	n := inotifyNotifier{
+		fd:     fd,
+		events: make(chan bool, 1),
+		done:   make(chan bool),
+	}
+	go n.read()
+	return &n, nil
+}
+
+func (n *inotifyNotifier) Add(dir string) error {
+	_, err := unix.InotifyAddWatch(n.fd, dir, inotifyMask)
+	return err
+}
+
+func (n *inotifyNotifier) Events() <-chan bool {
+	return n.events
+}
+
+func (n *inotifyNotifier) Close() {
+	close(n.done)
+}
+
+func (n *inotifyNotifier) read() {
+	defer unix.Close(n.fd)
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(n.fd), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-n.done:
+			return
+		default:
+		}
+		// poll with a timeout so that closing is noticed
+		ready, err := unix.Poll(fds, 500)
+		if err != nil && err != unix.EINTR {
+			return
+		}
+		if ready <= 0 {
+			continue
+		}
+		if read, _ := unix.Read(n.fd, buf); read > 0 {
+			select {
+			case n.events <- true:
+			default:
+				// a notification is already pending
+			}
+		}
+	}
+}
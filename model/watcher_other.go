@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package model
+
+import "errors"
+
+func newNotifier() (notifier, error) {
+	return nil, errors.New("filesystem notifications not supported on this platform")
+}
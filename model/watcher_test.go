@@ -0,0 +1,60 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thomgray/notebee/config"
+	"golang.org/x/net/html"
+)
+
+func TestHeadingPath(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<body><h1>Rebase</h1><h2>Interactive mode</h2><h3>Squash</h3></body>"))
+	doc := DocumentFromNode(node, "rebase")
+
+	squash := doc.Traverse([]string{"interactive", "mode", "squash"})
+	assert.Equal(t, []string{"Interactive", "mode", "Squash"}, squash.HeadingPath())
+	assert.Equal(t, []string{}, doc.HeadingPath())
+}
+
+func TestWatcherPicksUpChanges(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "notebee")
+	defer os.RemoveAll(dir)
+	writeNote(t, dir, "one.md", "# One\n\n## Section\n\nfirst")
+
+	fm := MakeFileManager(&config.Config{SearchPaths: []string{dir}})
+	fm.Reload()
+	fm.Traverse("one section")
+
+	changed := make(chan IndexChanges, 10)
+	w := fm.Watch(&sync.Mutex{}, func(ic IndexChanges) {
+		changed <- ic
+	})
+	defer w.Stop()
+
+	writeNote(t, dir, "sub/two.md", "# Two")
+	select {
+	case ic := <-changed:
+		assert.Equal(t, 1, len(ic.Added))
+	case <-time.After(WatchPollInterval * 3):
+		t.Fatal("watcher did not report the added file")
+	}
+
+	writeNote(t, dir, "one.md", "# One\n\n## Section\n\nedited")
+	select {
+	case ic := <-changed:
+		assert.True(t, ic.Touches(filepath.Join(dir, "one.md")))
+	case <-time.After(WatchPollInterval * 3):
+		t.Fatal("watcher did not report the changed file")
+	}
+
+	loc := fm.RefreshLocation()
+	assert.Equal(t, "Section", loc.Document.SearchTerm)
+	assert.Contains(t, fm.Index.Get(filepath.Join(dir, "one.md")).Text, "edited")
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorKind - what sort of failure an Error is
+type ErrorKind uint8
+
+const (
+	// ErrorNotFound - nothing matches what was asked for, e.g. a note or a bookmark
+	ErrorNotFound ErrorKind = iota
+	// ErrorInvalid - the input can't be acted on, e.g. a missing argument
+	ErrorInvalid
+	// ErrorFile - a file could not be read or written
+	ErrorFile
+)
+
+// Error - a failure to report to the user, with the reason in their terms and the error that caused it, if any
+type Error struct {
+	Kind   ErrorKind
+	Reason string
+	Cause  error
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Reason
+	}
+	return e.Reason + ": " + e.Cause.Error()
+}
+
+// Unwrap - the error that caused this one
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// NotFound - an error for something that doesn't exist, e.g. NotFound("no note matches '%s'", query)
+func NotFound(format string, a ...interface{}) *Error {
+	return &Error{Kind: ErrorNotFound, Reason: fmt.Sprintf(format, a...)}
+}
+
+// Invalid - an error for input that can't be acted on
+func Invalid(format string, a ...interface{}) *Error {
+	return &Error{Kind: ErrorInvalid, Reason: fmt.Sprintf(format, a...)}
+}
+
+// FileError - an error for a file that could not be read or written
+func FileError(cause error, format string, a ...interface{}) *Error {
+	return &Error{Kind: ErrorFile, Reason: fmt.Sprintf(format, a...), Cause: cause}
+}
+
+// IsErrorKind - is the error, or one it wraps, an Error of the kind
+func IsErrorKind(err error, kind ErrorKind) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == kind
+}
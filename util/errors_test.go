@@ -0,0 +1,25 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	err := NotFound("no note matches '%s'", "foo")
+	assert.Equal(t, "no note matches 'foo'", err.Error())
+	assert.True(t, IsErrorKind(err, ErrorNotFound))
+	assert.False(t, IsErrorKind(err, ErrorInvalid))
+
+	err = FileError(os.ErrPermission, "could not read %s", "notes/a.md")
+	assert.Equal(t, "could not read notes/a.md: permission denied", err.Error())
+	assert.True(t, errors.Is(err, os.ErrPermission))
+
+	wrapped := fmt.Errorf("loading: %w", err)
+	assert.True(t, IsErrorKind(wrapped, ErrorFile))
+	assert.False(t, IsErrorKind(errors.New("other"), ErrorFile))
+}
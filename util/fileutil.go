@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // ListFiles ...
@@ -30,22 +32,24 @@ func ListFiles(path string) []string {
 	return res
 }
 
+// ListFilesShort - the files in a directory, or none if it can't be read
 func ListFilesShort(path string) []os.FileInfo {
 	files, err := ioutil.ReadDir(path)
-
-	Check(err)
+	if err != nil {
+		log.Println(err)
+		return []os.FileInfo{}
+	}
 	return files
 }
 
-// ReadFile - reads a file contents. Empty bytes if file doesn't exist, second param is false is file doesn't exist.
-// Panics on any other error
-func ReadFile(path string) (data []byte, exists bool) {
+// ReadFile - reads a file's contents. Empty bytes and an error if it can't be read, which is os.IsNotExist
+// if the file doesn't exist
+func ReadFile(path string) ([]byte, error) {
 	bytes, err := ioutil.ReadFile(path)
-	if os.IsNotExist(err) {
-		return make([]byte, 0), false
+	if err != nil {
+		return make([]byte, 0), err
 	}
-	Check(err)
-	return bytes, true
+	return bytes, nil
 }
 
 func PathExists(path string) (os.FileInfo, bool) {
@@ -55,3 +59,50 @@ func PathExists(path string) (os.FileInfo, bool) {
 	}
 	return res, true
 }
+
+// AbbreviateHome - the path with the home directory replaced by ~, e.g. ~/notes
+func AbbreviateHome(path, home string) string {
+	path = filepath.Clean(path)
+	if home == "" {
+		return path
+	}
+	rel, err := filepath.Rel(home, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	if rel == "." {
+		return "~"
+	}
+	return filepath.Join("~", rel)
+}
+
+// ExpandHome - the path with a leading ~ replaced by the home directory, e.g. ~/notes
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path, err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// ResolveDirectory - the absolute path of a directory, as typed with or without a leading ~.
+// The error says why if it isn't a directory
+func ResolveDirectory(path string) (string, error) {
+	expanded, err := ExpandHome(path)
+	if err != nil {
+		return path, &Error{Kind: ErrorInvalid, Reason: "could not expand " + path, Cause: err}
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return path, &Error{Kind: ErrorInvalid, Reason: "could not resolve " + path, Cause: err}
+	}
+	if info, exists := PathExists(abs); !exists || info == nil {
+		return abs, NotFound("%s does not exist", path)
+	} else if !info.IsDir() {
+		return abs, Invalid("%s is not a directory", path)
+	}
+	return abs, nil
+}
@@ -0,0 +1,14 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbbreviateHome(t *testing.T) {
+	assert.Equal(t, "~/notes", AbbreviateHome("/home/me/notes/", "/home/me"))
+	assert.Equal(t, "~", AbbreviateHome("/home/me", "/home/me"))
+	assert.Equal(t, "/home/meg/notes", AbbreviateHome("/home/meg/notes", "/home/me"))
+	assert.Equal(t, "/srv/notes", AbbreviateHome("/srv/notes", ""))
+}
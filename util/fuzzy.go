@@ -0,0 +1,111 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// scores for fuzzy matching, loosely following fzf:
+// matched characters at the start of words and in runs score higher, gaps between them cost
+const (
+	fuzzyScoreMatch       = 16
+	fuzzyBonusFirst       = 10
+	fuzzyBonusBoundary    = 8
+	fuzzyBonusCamel       = 7
+	fuzzyBonusConsecutive = 8
+	fuzzyPenaltyGapStart  = 3
+	fuzzyPenaltyGapExtend = 1
+)
+
+const fuzzyWordSeparators = "/-_. :"
+
+// FuzzyMatch - match the pattern as a case insensitive subsequence of s.
+// Returns the score of the best alignment and the rune indices of s that matched
+func FuzzyMatch(pattern, s string) (int, []int, bool) {
+	p := []rune(pattern)
+	for i, r := range p {
+		p[i] = unicode.ToLower(r)
+	}
+	runes := []rune(s)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	if len(p) == 0 {
+		return 0, []int{}, true
+	}
+	if len(p) > len(lower) {
+		return 0, nil, false
+	}
+
+	bonus := func(j int) int {
+		if j == 0 {
+			return fuzzyBonusFirst
+		}
+		prev := runes[j-1]
+		if strings.ContainsRune(fuzzyWordSeparators, prev) {
+			return fuzzyBonusBoundary
+		}
+		if unicode.IsLower(prev) && unicode.IsUpper(runes[j]) {
+			return fuzzyBonusCamel
+		}
+		return 0
+	}
+
+	// scores[i][j] is the best score with p[i] matched at j, and from[i][j] where p[i-1] matched
+	scores := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for i := range p {
+		scores[i] = make([]int, len(lower))
+		from[i] = make([]int, len(lower))
+		for j := range lower {
+			scores[i][j] = -1
+			from[i][j] = -1
+			if lower[j] != p[i] {
+				continue
+			}
+			if i == 0 {
+				scores[i][j] = fuzzyScoreMatch + bonus(j)
+				continue
+			}
+			best, bestK := -1, -1
+			for k := i - 1; k < j; k++ {
+				if scores[i-1][k] < 0 {
+					continue
+				}
+				v := scores[i-1][k]
+				if k == j-1 {
+					v += fuzzyBonusConsecutive
+				} else {
+					v -= fuzzyPenaltyGapStart + (j-k-2)*fuzzyPenaltyGapExtend
+				}
+				if v > best {
+					best, bestK = v, k
+				}
+			}
+			if bestK >= 0 {
+				// keep scores of valid alignments non-negative so -1 always means no match
+				scores[i][j] = MaxInt(best, 0) + fuzzyScoreMatch + bonus(j)
+				from[i][j] = bestK
+			}
+		}
+	}
+
+	last := len(p) - 1
+	best, bestJ := -1, -1
+	for j, v := range scores[last] {
+		if v > best {
+			best, bestJ = v, j
+		}
+	}
+	if bestJ < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, len(p))
+	for i, j := last, bestJ; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+	return best, positions, true
+}
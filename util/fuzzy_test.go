@@ -0,0 +1,35 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	score, positions, ok := FuzzyMatch("kubro", "kubernetes/rollouts")
+	assert.True(t, ok)
+	assert.True(t, score > 0)
+	assert.Equal(t, []int{0, 1, 2, 11, 12}, positions)
+
+	_, _, ok = FuzzyMatch("kubrox", "kubernetes/rollouts")
+	assert.False(t, ok)
+
+	_, positions, ok = FuzzyMatch("", "anything")
+	assert.True(t, ok)
+	assert.Equal(t, []int{}, positions)
+}
+
+func TestFuzzyMatchPrefersWordStartsAndRuns(t *testing.T) {
+	// "gr" at the start of the segment rather than the "g" in the middle of a word
+	_, positions, _ := FuzzyMatch("gr", "bigdata/graphs")
+	assert.Equal(t, []int{8, 9}, positions)
+
+	runs, _, _ := FuzzyMatch("roll", "k8s/rollouts")
+	scattered, _, _ := FuzzyMatch("roll", "rust/ownership-lifetimes-lifecycle")
+	assert.True(t, runs > scattered)
+
+	camel, _, _ := FuzzyMatch("gb", "GitBranches")
+	plain, _, _ := FuzzyMatch("gb", "gitbranches")
+	assert.True(t, camel > plain)
+}
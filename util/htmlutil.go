@@ -8,7 +8,7 @@ import (
 )
 
 func MarkdownToNode(data []byte) (*html.Node, error) {
-	md := blackfriday.Run(data)
+	md := blackfriday.Run(data, blackfriday.WithExtensions(blackfriday.CommonExtensions|blackfriday.Footnotes))
 	node, err := html.Parse(strings.NewReader(string(md)))
 
 	return HTMLBody(node), err
@@ -36,3 +36,30 @@ func HTMLBody(n *html.Node) *html.Node {
 	f(n)
 	return body
 }
+
+var __plainTextBlockTags = []string{
+	"p", "h1", "h2", "h3", "h4", "h5", "h6", "pre", "li", "blockquote", "tr", "hr", "dt", "dd",
+}
+
+// PlainText - the text content of a node, with block level elements on separate lines
+func PlainText(n *html.Node) string {
+	var sb strings.Builder
+	var f func(node *html.Node)
+	f = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			// whitespace between blocks is just formatting
+			if !(strings.Contains(node.Data, "\n") && strings.TrimSpace(node.Data) == "") {
+				sb.WriteString(node.Data)
+			}
+			return
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+		if node.Type == html.ElementNode && StringSliceContains(__plainTextBlockTags, node.Data) {
+			sb.WriteString("\n")
+		}
+	}
+	f(n)
+	return sb.String()
+}
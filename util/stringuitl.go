@@ -142,3 +142,32 @@ func StringSliceContains(slice []string, s string) bool {
 	}
 	return false
 }
+
+// EditDistance - the optimal string alignment distance between two strings:
+// the number of rune insertions, deletions, substitutions and adjacent transpositions to turn one into the other
+func EditDistance(s1, s2 string) int {
+	a := []rune(s1)
+	b := []rune(s2)
+	// three rows are enough to account for transpositions
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
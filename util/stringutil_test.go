@@ -59,3 +59,12 @@ func TestLongestCommonPrefix(t *testing.T) {
 	res2 := LongestCommonPrefix("not", "here")
 	assert.Equal(t, "", res2)
 }
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance("rebase", "rebase"))
+	assert.Equal(t, 1, EditDistance("rebase", "rebsae"))
+	assert.Equal(t, 1, EditDistance("rebase", "rebas"))
+	assert.Equal(t, 2, EditDistance("kitten", "sitten!"))
+	assert.Equal(t, 3, EditDistance("", "abc"))
+	assert.Equal(t, 1, EditDistance("café", "cafe"))
+}
@@ -6,3 +6,11 @@ func Check(err error) {
 		panic(err)
 	}
 }
+
+// MaxInt - the greater of two ints
+func MaxInt(i1, i2 int) int {
+	if i1 > i2 {
+		return i1
+	}
+	return i2
+}
@@ -111,21 +111,47 @@ func (cv *CompletionView) draw(c egg.Canvas) {
 				bg = selectedBg
 			}
 
+			matched := make(map[int]bool)
+			for _, m := range compl.Matches {
+				matched[m] = true
+			}
+
+			if compl.Kind == model.AutocompleteKindHeading {
+				cv.drawHeading(c, compl, i, isSelected, bg)
+				continue
+			}
+			if compl.Kind == model.AutocompleteKindBookmark {
+				cv.drawBookmark(c, compl, i, isSelected, bg, matched)
+				continue
+			}
+
 			pieces := strings.Split(compl.Str, string(os.PathSeparator))
 			lastPieceI := len(pieces) - 1
 			x := 0
+			runeI := 0
 			for ii, piece := range pieces {
 				fg := egg.ColorCyan
 				isFinalPiece := ii == lastPieceI
 				if isSelected {
 					fg = selectedFg
-				} else if isFinalPiece && !compl.IsDir {
+				} else if isFinalPiece && compl.Kind == model.AutocompleteKindCommand {
+					fg = egg.ColorMagenta
+				} else if isFinalPiece && !compl.IsDir() {
 					fg = c.Foreground
 				}
-				c.DrawString(piece, x, i, fg, bg, c.Attribute)
+				for _, r := range piece {
+					if matched[runeI] {
+						cv.drawMatchedRune(c, r, x, i, isSelected, bg)
+					} else {
+						c.DrawRune(r, x, i, fg, bg, c.Attribute)
+					}
+					x += runewidth.RuneWidth(r)
+					runeI++
+				}
+				// the separator
+				runeI++
 
-				x += runewidth.StringWidth(piece)
-				if !isFinalPiece || compl.IsDir {
+				if !isFinalPiece || compl.IsDir() {
 					slashFg := egg.ColorBrightMagenta
 					if isSelected {
 						slashFg = selectedFg
@@ -144,3 +170,40 @@ func (cv *CompletionView) draw(c egg.Canvas) {
 	c.DrawString(strings.Repeat("─", c.Width), 0, y, egg.ColorBlue, c.Background, c.Attribute)
 
 }
+
+// headings are green, after the note and any headings already typed
+func (cv *CompletionView) drawHeading(c egg.Canvas, compl model.AutocompleteResult, y int, isSelected bool, bg egg.Color) {
+	context := strings.TrimSuffix(compl.Str, compl.Heading)
+	contextFg, headingFg := egg.ColorBrightBlack, egg.ColorGreen
+	if isSelected {
+		contextFg, headingFg = egg.ColorBlack, egg.ColorBlack
+	}
+	c.DrawString(context, 0, y, contextFg, bg, c.Attribute)
+	c.DrawString(compl.Heading, runewidth.StringWidth(context), y, headingFg, bg, c.Attribute|egg.AttrBold)
+}
+
+// bookmarks are yellow
+func (cv *CompletionView) drawBookmark(c egg.Canvas, compl model.AutocompleteResult, y int, isSelected bool, bg egg.Color, matched map[int]bool) {
+	fg := egg.ColorYellow
+	if isSelected {
+		fg = egg.ColorBlack
+	}
+	x := 0
+	for i, r := range []rune(compl.Str) {
+		if matched[i] {
+			cv.drawMatchedRune(c, r, x, y, isSelected, bg)
+		} else {
+			c.DrawRune(r, x, y, fg, bg, c.Attribute)
+		}
+		x += runewidth.RuneWidth(r)
+	}
+}
+
+// matched characters stand out in yellow, or underlined on the selected row
+func (cv *CompletionView) drawMatchedRune(c egg.Canvas, r rune, x, y int, isSelected bool, bg egg.Color) {
+	if isSelected {
+		c.DrawRune(r, x, y, egg.ColorBlack, bg, c.Attribute|egg.AttrBold|egg.AttrUnderline)
+	} else {
+		c.DrawRune(r, x, y, egg.ColorYellow, bg, c.Attribute|egg.AttrBold)
+	}
+}
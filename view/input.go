@@ -32,6 +32,8 @@ func MakeInputView(app *egg.Application) *InputView {
 			char = "?"
 		case constants.InputModeCommand:
 			char = ":"
+		case constants.InputModeFind:
+			char = "/"
 		}
 		c.DrawString(char, 0, 0, egg.ColorCyan, c.Background, c.Attribute)
 	})
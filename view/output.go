@@ -6,13 +6,28 @@ import (
 	"github.com/thomgray/egg"
 	"github.com/thomgray/notebee/htmlrender"
 	"github.com/thomgray/notebee/model"
+	"golang.org/x/net/html"
 )
 
 type OutputView struct {
 	*egg.View
-	doc        *model.Document
-	file       *model.File
-	customDraw func(egg.Canvas)
+	doc          *model.Document
+	file         *model.File
+	customDraw   func(egg.Canvas)
+	custom       bool
+	highlight    model.Highlighter
+	lineNumbers  bool
+	numberLinks  bool
+	selectedLink *html.Node
+	scrollTarget *html.Node
+	scrollY      *int
+	scroller     func(int)
+	revealer     func(int)
+	finding      bool
+	currentMatch int
+	findFrom     *int
+	revealIndex  *int
+	rendered     htmlrender.Result
 }
 
 func MakeOutputView() *OutputView {
@@ -27,24 +42,139 @@ func MakeOutputView() *OutputView {
 }
 
 func (ov *OutputView) UnbindDraw() {
+	ov.custom = false
 	ov.View.OnDraw(ov.draw)
 }
 
 func (ov *OutputView) CustomDraw(f func(egg.Canvas)) {
+	ov.custom = true
 	ov.View.OnDraw(f)
 }
 
+// ShowingNote - is a note displayed, rather than the output of a command
+func (ov *OutputView) ShowingNote() bool {
+	return ov.file != nil && !ov.custom
+}
+
 func (ov *OutputView) SetDocument(f *model.Document) {
 	ov.doc = f
 	ov.UnbindDraw()
 }
 
 func (ov *OutputView) SetFile(f *model.File) {
-	ov.file = f
+	ov.SetFileDocument(f, nil)
+}
+
+// SetFileDocument - set the file, and the document (section) within it to render.
+// A nil document renders the whole file
+func (ov *OutputView) SetFileDocument(f *model.File, doc *model.Document) {
+	ov.UpdateFileDocument(f, doc)
+	ov.UnbindDraw()
+	ov.highlight = nil
+	ov.finding = false
+	ov.selectedLink = nil
+	ov.scrollTarget = nil
+	ov.scrollY = nil
+	ov.revealIndex = nil
 	bnds := ov.GetBounds()
 	bnds.Origin.Y = 0
 	ov.SetBounds(bnds)
-	ov.UnbindDraw()
+}
+
+// UpdateFileDocument - replace the file/document being rendered, keeping the scroll position.
+// Use this when the same document has been reloaded. The output of a command stays on screen if it is showing
+func (ov *OutputView) UpdateFileDocument(f *model.File, doc *model.Document) {
+	ov.file = f
+	ov.doc = doc
+}
+
+// SetHighlight - highlight the ranges of rendered text found by the highlighter
+func (ov *OutputView) SetHighlight(h model.Highlighter) {
+	ov.highlight = h
+}
+
+// SetLineNumbers - number the lines of code blocks
+func (ov *OutputView) SetLineNumbers(on bool) {
+	ov.lineNumbers = on
+}
+
+// SetNumberLinks - number links, listing their hrefs at the end instead of inline
+func (ov *OutputView) SetNumberLinks(on bool) {
+	ov.numberLinks = on
+}
+
+// SetSelectedLink - draw the anchor as selected. nil selects nothing
+func (ov *OutputView) SetSelectedLink(n *html.Node) {
+	ov.selectedLink = n
+}
+
+// Links - the links drawn by the last render, in the order they were drawn
+func (ov *OutputView) Links() []htmlrender.LinkPosition {
+	return ov.rendered.Links
+}
+
+// Find - highlight every match of the highlighter, starting from the first match at or below the offset y
+func (ov *OutputView) Find(h model.Highlighter, y int) {
+	ov.highlight = h
+	ov.finding = true
+	ov.currentMatch = 0
+	ov.findFrom = &y
+}
+
+// ClearFind - stop highlighting the matches of a find
+func (ov *OutputView) ClearFind() {
+	if !ov.finding {
+		return
+	}
+	ov.highlight = nil
+	ov.finding = false
+	ov.currentMatch = 0
+	ov.findFrom = nil
+}
+
+// FindStatus - the number of the current match and the number of matches, if finding
+func (ov *OutputView) FindStatus() (int, int, bool) {
+	return ov.currentMatch, len(ov.rendered.Matches), ov.finding
+}
+
+// CycleMatch - make the next match current, or the previous for a negative step, wrapping around,
+// and scroll it into view
+func (ov *OutputView) CycleMatch(step int) {
+	matches := ov.rendered.Matches
+	if !ov.finding || len(matches) == 0 {
+		return
+	}
+	n := len(matches)
+	switch {
+	case ov.currentMatch > 0:
+		ov.currentMatch = (ov.currentMatch-1+step%n+n)%n + 1
+	case step < 0:
+		ov.currentMatch = n
+	default:
+		ov.currentMatch = 1
+	}
+	ov.revealMatch()
+}
+
+func (ov *OutputView) revealMatch() {
+	if ov.revealer != nil && ov.currentMatch > 0 {
+		ov.revealer(ov.rendered.Matches[ov.currentMatch-1].Y)
+	}
+}
+
+// ScrollToNode - scroll so that the node (a heading) is at the top, once it has been rendered
+func (ov *OutputView) ScrollToNode(n *html.Node) {
+	ov.scrollTarget = n
+}
+
+// RevealHighlight - scroll the highlighted match with the index (counting from 0) into view, once it has been rendered
+func (ov *OutputView) RevealHighlight(i int) {
+	ov.revealIndex = &i
+}
+
+// ScrollToY - scroll so that the offset y is at the top, once the document has been rendered
+func (ov *OutputView) ScrollToY(y int) {
+	ov.scrollY = &y
 }
 
 func (ov *OutputView) draw(c egg.Canvas) {
@@ -61,17 +191,76 @@ func (ov *OutputView) drawFile(c egg.Canvas) {
 		log.Println("File is null, nothing to render")
 		return
 	}
-	node := f.Body
-	if node == nil {
-		log.Println("Node is null, nothing to render")
-		return
+	var nodes []*html.Node
+	if ov.doc != nil && ov.doc.IsSection() {
+		nodes = ov.doc.Content
+	} else {
+		if f.Body == nil {
+			log.Println("Node is null, nothing to render")
+			return
+		}
+		nodes = []*html.Node{f.Body}
 	}
-
-	h := htmlrender.RenderHtml(node, c) + 1
+	opts := htmlrender.Options{
+		Highlight:    ov.highlight,
+		LineNumbers:  ov.lineNumbers,
+		NumberLinks:  ov.numberLinks,
+		SelectedLink: ov.selectedLink,
+		CurrentMatch: ov.currentMatch,
+	}
+	ov.rendered = htmlrender.Render(nodes, c, opts)
+	h := ov.rendered.Height + 1
 	if ov.GetBounds().Height != h {
 		newb := ov.GetBounds()
 		newb.Height = h
 		ov.SetBounds(newb)
 		app.ReDraw()
 	}
+
+	// the position of the target is only known now it has been rendered
+	if ov.scrollTarget != nil && ov.scroller != nil {
+		if y, ok := ov.rendered.HeadingY(ov.scrollTarget); ok {
+			ov.scrollTarget = nil
+			ov.scroller(y)
+			app.ReDraw()
+		}
+	}
+	// the matches of a find are only known now they have been rendered
+	if ov.currentMatch > len(ov.rendered.Matches) {
+		ov.currentMatch = len(ov.rendered.Matches)
+	}
+	if ov.findFrom != nil {
+		ov.currentMatch = firstMatchFrom(ov.rendered.Matches, *ov.findFrom)
+		ov.findFrom = nil
+		ov.revealMatch()
+		app.ReDraw()
+	}
+	if ov.revealIndex != nil && ov.revealer != nil && len(ov.rendered.Matches) > 0 {
+		i := *ov.revealIndex
+		ov.revealIndex = nil
+		if i >= len(ov.rendered.Matches) {
+			i = len(ov.rendered.Matches) - 1
+		}
+		ov.revealer(ov.rendered.Matches[i].Y)
+		app.ReDraw()
+	}
+	if ov.scrollY != nil && ov.scroller != nil {
+		y := *ov.scrollY
+		ov.scrollY = nil
+		ov.scroller(y)
+		app.ReDraw()
+	}
+}
+
+// the number of the first match at or below y, wrapping around to the first match. 0 if there are none
+func firstMatchFrom(matches []htmlrender.TextPosition, y int) int {
+	for i, m := range matches {
+		if m.Y >= y {
+			return i + 1
+		}
+	}
+	if len(matches) > 0 {
+		return 1
+	}
+	return 0
 }
@@ -2,16 +2,23 @@ package view
 
 import (
 	"log"
+	"strings"
 
+	"github.com/mattn/go-runewidth"
 	"github.com/thomgray/egg"
 	"github.com/thomgray/notebee/model"
 )
 
+// each result is drawn as the path, then a snippet of the matching text
+const searchResultHeight = 2
+
 type SearchResultsView struct {
 	*egg.View
 	Items     []*model.SearchResultItem
 	itemIndex int
 	open      bool
+	// ambiguous - query paths (lower case) of more than one result, which are drawn with the alias of their root
+	ambiguous map[string]bool
 }
 
 func (sv SearchResultsView) New() *SearchResultsView {
@@ -24,7 +31,7 @@ func (sv SearchResultsView) New() *SearchResultsView {
 
 func (sv *SearchResultsView) Refit(w, h int) {
 	anchor := 1
-	sv.SetBounds(egg.MakeBounds(0, anchor, w, len(sv.Items)))
+	sv.SetBounds(egg.MakeBounds(0, anchor, w, len(sv.Items)*searchResultHeight))
 }
 
 func (sv *SearchResultsView) handleKeyEvent(e *egg.KeyEvent) {
@@ -33,9 +40,17 @@ func (sv *SearchResultsView) handleKeyEvent(e *egg.KeyEvent) {
 
 func (sv *SearchResultsView) SetItems(items []*model.SearchResultItem) {
 	bnds := sv.GetBounds()
-	bnds.Height = len(items)
+	bnds.Height = len(items) * searchResultHeight
 	sv.SetBounds(bnds)
 	sv.Items = items
+	counts := make(map[string]int)
+	for _, item := range items {
+		counts[strings.ToLower(item.Path.QueryPath())]++
+	}
+	sv.ambiguous = make(map[string]bool)
+	for qp, n := range counts {
+		sv.ambiguous[qp] = n > 1
+	}
 }
 
 func (sv *SearchResultsView) Open() {
@@ -77,10 +92,39 @@ func (sv *SearchResultsView) Prev() {
 
 func (sv *SearchResultsView) draw(c egg.Canvas) {
 	for i, res := range sv.Items {
+		y := i * searchResultHeight
 		bg := c.Background
 		if i == sv.itemIndex {
 			bg = egg.ColorBrightCyan
 		}
-		c.DrawString(res.Path.QueryPath(), 0, i, c.Foreground, bg, c.Attribute)
+		c.DrawString(sv.resultName(res), 0, y, c.Foreground, bg, c.Attribute)
+		drawSnippet(c, res.Snippet, res.SnippetMatches, 2, y+1)
+	}
+}
+
+// the query path of a result, qualified with its root's alias if another result has the same query path
+func (sv *SearchResultsView) resultName(res *model.SearchResultItem) string {
+	if sv.ambiguous[strings.ToLower(res.Path.QueryPath())] {
+		return res.Path.QualifiedQueryPath()
+	}
+	return res.Path.QueryPath()
+}
+
+// draw the snippet, highlighting the matches
+func drawSnippet(c egg.Canvas, snippet string, matches []model.TextRange, x, y int) {
+	matchI := 0
+	for i, r := range snippet {
+		if x >= c.Width {
+			return
+		}
+		for matchI < len(matches) && matches[matchI].End <= i {
+			matchI++
+		}
+		fg := egg.ColorBrightBlack
+		if matchI < len(matches) && matches[matchI].Start <= i {
+			fg = egg.ColorYellow
+		}
+		c.DrawRune(r, x, y, fg, c.Background, c.Attribute)
+		x += runewidth.RuneWidth(r)
 	}
 }
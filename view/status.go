@@ -0,0 +1,123 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/constants"
+)
+
+// StatusView - a line below the input showing the mode, the note and section in view, how far it is scrolled,
+// the document root, and messages such as errors
+type StatusView struct {
+	*egg.View
+	main    *MainView
+	mode    constants.ActiveMode
+	path    string
+	root    string
+	message string
+	isError bool
+}
+
+// MakeStatusView ...
+func MakeStatusView(main *MainView) *StatusView {
+	sv := StatusView{
+		View: egg.MakeView(),
+		main: main,
+	}
+	sv.OnDraw(sv.draw)
+	sv.Refit(egg.WindowSize())
+	return &sv
+}
+
+// Refit - fit the status line to the window width
+func (sv *StatusView) Refit(w, h int) {
+	sv.SetBounds(egg.MakeBounds(0, 1, w, 1))
+}
+
+// SetMode - the active mode to show
+func (sv *StatusView) SetMode(mode constants.ActiveMode) {
+	sv.mode = mode
+}
+
+// SetPath - the query path of the note being displayed, or "" for none
+func (sv *StatusView) SetPath(path string) {
+	sv.path = path
+}
+
+// SetRoot - the document root to show, or "" for none
+func (sv *StatusView) SetRoot(root string) {
+	sv.root = root
+}
+
+// SetMessage - show a message until the next key press
+func (sv *StatusView) SetMessage(msg string) {
+	sv.message = msg
+	sv.isError = false
+}
+
+// SetError - show an error until the next key press
+func (sv *StatusView) SetError(msg string) {
+	sv.message = msg
+	sv.isError = true
+}
+
+// ClearMessage - stop showing any message or error
+func (sv *StatusView) ClearMessage() {
+	sv.message = ""
+	sv.isError = false
+}
+
+type statusSegment struct {
+	text string
+	fg   egg.Color
+}
+
+func (sv *StatusView) draw(c egg.Canvas) {
+	mode := " " + constants.ActiveModeNames[sv.mode] + " "
+	c.DrawString(mode, 0, 0, egg.ColorBlack, egg.ColorCyan, c.Attribute)
+	x := runewidth.StringWidth(mode) + 1
+
+	right := make([]statusSegment, 0)
+	if sv.message != "" {
+		fg := egg.ColorGreen
+		if sv.isError {
+			fg = egg.ColorRed
+		}
+		right = append(right, statusSegment{sv.message, fg})
+	}
+	if current, total, finding := sv.main.OutputView.FindStatus(); finding {
+		status := "no matches"
+		if total > 0 {
+			status = fmt.Sprintf("%d/%d", current, total)
+		}
+		right = append(right, statusSegment{status, egg.ColorYellow})
+	}
+	if sv.path != "" && sv.main.OutputView.ShowingNote() {
+		scrolled := "all"
+		if pct, ok := sv.main.ScrollPercent(); ok {
+			scrolled = fmt.Sprintf("%d%%", pct)
+		}
+		right = append(right, statusSegment{scrolled, c.Foreground})
+	}
+	if sv.root != "" {
+		right = append(right, statusSegment{sv.root, egg.ColorBrightBlack})
+	}
+
+	rightX := c.Width - 1
+	for i := len(right) - 1; i >= 0; i-- {
+		rightX -= runewidth.StringWidth(right[i].text)
+		c.DrawString(right[i].text, rightX, 0, right[i].fg, c.Background, c.Attribute)
+		rightX -= 2
+	}
+
+	if sv.path == "" {
+		return
+	}
+	place := append([]string{sv.path}, sv.main.HeadingPathInView()...)
+	if w := rightX - x; w > 0 {
+		c.DrawString(runewidth.Truncate(strings.Join(place, " › "), w, "…"), x, 0, egg.ColorCyan, c.Background, c.Attribute)
+	}
+}
@@ -0,0 +1,186 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/thomgray/egg"
+	"github.com/thomgray/notebee/model"
+	"golang.org/x/net/html"
+)
+
+// TOCView - the outline of the active note's headings, beside the output
+type TOCView struct {
+	*egg.View
+	doc *model.Document
+	// path - the file of the note being outlined
+	path string
+	// collapsed - by heading path, so that collapsing survives the note being reloaded
+	collapsed map[string]bool
+	entries   []model.OutlineEntry
+	selected  int
+	focused   bool
+	offset    int
+	// current - the heading at the top of the output
+	current func() *html.Node
+}
+
+// MakeTOCView ...
+func MakeTOCView(current func() *html.Node) *TOCView {
+	tv := TOCView{
+		View:      egg.MakeView(),
+		collapsed: make(map[string]bool),
+		current:   current,
+	}
+	tv.OnDraw(tv.draw)
+	tv.SetVisible(false)
+	return &tv
+}
+
+func outlineKey(doc *model.Document) string {
+	return strings.Join(doc.HeadingTitles(), "\x00")
+}
+
+// SetDocument - outline the document of the file at path, keeping headings collapsed if it is the same file
+func (tv *TOCView) SetDocument(path string, doc *model.Document) {
+	if tv.doc == nil || doc == nil || tv.path != path {
+		tv.collapsed = make(map[string]bool)
+		tv.selected = 0
+		tv.offset = 0
+	}
+	tv.path = path
+	tv.doc = doc
+	tv.rebuild()
+}
+
+func (tv *TOCView) rebuild() {
+	tv.entries = tv.doc.Outline(func(d *model.Document) bool {
+		return tv.collapsed[outlineKey(d)]
+	})
+	if tv.selected >= len(tv.entries) {
+		tv.selected = len(tv.entries) - 1
+	}
+	if tv.selected < 0 {
+		tv.selected = 0
+	}
+}
+
+// Focus - select entries with the keyboard, starting from the section in view
+func (tv *TOCView) Focus(on bool) {
+	if on && !tv.focused {
+		if i := tv.currentIndex(); i >= 0 {
+			tv.selected = i
+		}
+	}
+	tv.focused = on
+}
+
+// IsEmpty - does the note have no headings to list
+func (tv *TOCView) IsEmpty() bool {
+	return len(tv.entries) == 0
+}
+
+// MoveSelection - select the entry step entries down, or up for a negative step
+func (tv *TOCView) MoveSelection(step int) {
+	tv.selected += step
+	if tv.selected >= len(tv.entries) {
+		tv.selected = len(tv.entries) - 1
+	}
+	if tv.selected < 0 {
+		tv.selected = 0
+	}
+}
+
+// Selected - the document of the selected entry, or nil
+func (tv *TOCView) Selected() *model.Document {
+	if tv.selected < 0 || tv.selected >= len(tv.entries) {
+		return nil
+	}
+	return tv.entries[tv.selected].Document
+}
+
+// SetCollapsed - hide or show the headings under the selected entry
+func (tv *TOCView) SetCollapsed(collapse bool) {
+	doc := tv.Selected()
+	if doc == nil || len(doc.SubDocuments) == 0 {
+		return
+	}
+	if collapse {
+		tv.collapsed[outlineKey(doc)] = true
+	} else {
+		delete(tv.collapsed, outlineKey(doc))
+	}
+	tv.rebuild()
+}
+
+// the index of the entry for the section in view. If the section is under a collapsed heading, that heading's entry
+func (tv *TOCView) currentIndex() int {
+	if tv.doc == nil || tv.current == nil {
+		return -1
+	}
+	n := tv.current()
+	if n == nil {
+		return -1
+	}
+	for d := tv.doc.FindHeading(n); d != nil; d = d.Super {
+		for i, e := range tv.entries {
+			if e.Document == d {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (tv *TOCView) draw(c egg.Canvas) {
+	textW := c.Width - 1
+	for y := 0; y < c.Height; y++ {
+		c.DrawString("│", textW, y, egg.ColorBrightBlack, c.Background, c.Attribute)
+	}
+	if tv.doc == nil {
+		return
+	}
+	c.DrawString(runewidth.Truncate(tv.doc.SearchTerm, textW, "…"), 0, 0, c.Foreground, c.Background, c.Attribute|egg.AttrBold)
+
+	current := tv.currentIndex()
+	listH := c.Height - 1
+	tv.scrollToShow(current, listH)
+	if tv.focused {
+		tv.scrollToShow(tv.selected, listH)
+	}
+
+	for i := tv.offset; i < len(tv.entries) && i-tv.offset < listH; i++ {
+		e := tv.entries[i]
+		y := i - tv.offset + 1
+		marker := "  "
+		if e.HasChildren && tv.collapsed[outlineKey(e.Document)] {
+			marker = "▸ "
+		} else if e.HasChildren {
+			marker = "▾ "
+		}
+		line := strings.Repeat("  ", e.Depth) + marker + e.Document.SearchTerm
+		line = runewidth.Truncate(line, textW, "…")
+
+		fg, bg, attr := c.Foreground, c.Background, c.Attribute
+		if i == current {
+			fg, attr = egg.ColorCyan, attr|egg.AttrBold
+		}
+		if tv.focused && i == tv.selected {
+			fg, bg = egg.ColorBlack, egg.ColorBlue
+			line += strings.Repeat(" ", textW-runewidth.StringWidth(line))
+		}
+		c.DrawString(line, 0, y, fg, bg, attr)
+	}
+}
+
+// scroll the list so that the entry is within the height
+func (tv *TOCView) scrollToShow(i, height int) {
+	if i < 0 || height <= 0 {
+		return
+	}
+	if i < tv.offset {
+		tv.offset = i
+	} else if i >= tv.offset+height {
+		tv.offset = i - height + 1
+	}
+}
@@ -3,14 +3,20 @@ package view
 import (
 	"github.com/thomgray/egg"
 	"github.com/thomgray/egg/eggc"
+	"github.com/thomgray/notebee/htmlrender"
 	"github.com/thomgray/notebee/model"
+	"golang.org/x/net/html"
 )
 
 // MainView ...
 type MainView struct {
 	OutputView *OutputView
 	ScrollView *eggc.ScrollView
+	TOCView    *TOCView
 	activeFile *model.File
+	activeDoc  *model.Document
+	width      int
+	height     int
 }
 
 var app *egg.Application
@@ -22,10 +28,14 @@ func MakeMainView(application *egg.Application) *MainView {
 		OutputView: MakeOutputView(),
 		ScrollView: eggc.MakeScrollView(),
 	}
+	mv.TOCView = MakeTOCView(mv.headingInView)
 	mv.fitToWindow()
+	mv.OutputView.scroller = mv.ScrollTo
+	mv.OutputView.revealer = mv.Reveal
 
 	mv.ScrollView.AddSubView(mv.OutputView.View)
 	app.AddViewController(mv.ScrollView)
+	app.AddView(mv.TOCView.View)
 	// app.OnResizeEvent(func(re *egg.ResizeEvent) {
 	// 	mv.resize(re.Width, re.Height)
 	// 	app.ReDraw()
@@ -33,15 +43,16 @@ func MakeMainView(application *egg.Application) *MainView {
 	return &mv
 }
 
-// Refit
+// Refit - fit the views to the window, with the table of contents on the left if it is visible
 func (mv *MainView) Refit(w, h int) {
-	mv.ScrollView.SetBounds(egg.MakeBounds(0, 2, w, h-2))
-	mv.OutputView.SetBounds(egg.MakeBounds(0, 0, w, h-2))
+	mv.width, mv.height = w, h
+	mv.split()
+	mv.OutputView.SetBounds(egg.MakeBounds(0, 0, mv.ScrollView.GetBounds().Width-1, h-2))
 }
 
 func (mv *MainView) fitToWindow() {
-	w, h := egg.WindowSize()
-	mv.ScrollView.SetBounds(egg.MakeBounds(0, 2, w, h-2))
+	mv.width, mv.height = egg.WindowSize()
+	mv.split()
 	mv.refit()
 }
 
@@ -51,6 +62,43 @@ func (mv *MainView) refit() {
 	mv.OutputView.SetBounds(egg.MakeBounds(0, outputY, bs.Width-1, bs.Height-outputY))
 }
 
+// the width of the table of contents: a quarter of the window, within limits
+func tocWidth(w int) int {
+	tw := w / 4
+	if tw < 16 {
+		tw = 16
+	}
+	if tw > 32 {
+		tw = 32
+	}
+	return tw
+}
+
+// split - lay out the table of contents and the scroll view side by side
+func (mv *MainView) split() {
+	x := 0
+	if mv.TOCView.IsVisible() {
+		x = tocWidth(mv.width)
+		mv.TOCView.SetBounds(egg.MakeBounds(0, 2, x, mv.height-2))
+		x++
+	}
+	mv.ScrollView.SetBounds(egg.MakeBounds(x, 2, mv.width-x, mv.height-2))
+}
+
+// SetTOCVisible - show or hide the table of contents, keeping the output scrolled where it is
+func (mv *MainView) SetTOCVisible(on bool) {
+	mv.TOCView.SetVisible(on)
+	mv.split()
+	b := mv.OutputView.GetBounds()
+	b.Width = mv.ScrollView.GetBounds().Width - 1
+	mv.OutputView.SetBounds(b)
+}
+
+// the heading at the top of the viewport
+func (mv *MainView) headingInView() *html.Node {
+	return mv.OutputView.rendered.HeadingAt(mv.ScrollOffset())
+}
+
 // func (mv *MainView) SetActiveDocument(doc *model.Document) {
 // 	mv.activeDocument = doc
 
@@ -68,11 +116,119 @@ func (mv *MainView) refit() {
 // }
 
 func (mv *MainView) SetActiveFile(file *model.File) {
+	mv.SetActiveDocument(file, nil)
+}
+
+// SetActiveDocument - display a section of a file. A nil document displays the whole file
+func (mv *MainView) SetActiveDocument(file *model.File, doc *model.Document) {
 	mv.activeFile = file
-	mv.OutputView.SetFile(file)
+	mv.activeDoc = doc
+	mv.OutputView.SetFileDocument(file, doc)
+	if file != nil {
+		mv.TOCView.SetDocument(file.Path, file.Document)
+	}
 	mv.refit()
 }
 
+// UpdateActiveDocument - swap in a reloaded version of the active document without scrolling
+func (mv *MainView) UpdateActiveDocument(file *model.File, doc *model.Document) {
+	mv.activeFile = file
+	mv.activeDoc = doc
+	mv.OutputView.UpdateFileDocument(file, doc)
+	if file != nil {
+		mv.TOCView.SetDocument(file.Path, file.Document)
+	}
+}
+
+// ScrollTo - scroll the output so that y is at the top of the viewport, as far as the content allows
+func (mv *MainView) ScrollTo(y int) {
+	visible := mv.ScrollView.GetViewport().Height
+	b := mv.OutputView.GetBounds()
+	if maxY := b.Height - visible; y > maxY {
+		y = maxY
+	}
+	if y < 0 {
+		y = 0
+	}
+	b.Y = -y
+	mv.OutputView.SetBounds(b)
+}
+
+// lines shown above a line scrolled into view by Reveal
+const revealContext = 2
+
+// Reveal - scroll the offset y into view if it is outside the viewport, with a little context above it
+func (mv *MainView) Reveal(y int) {
+	top := mv.ScrollOffset()
+	if y < top || y >= top+mv.ScrollView.GetViewport().Height {
+		mv.ScrollTo(y - revealContext)
+	}
+}
+
+// ScrollLines - scroll the output down n lines, or up for a negative n
+func (mv *MainView) ScrollLines(n int) {
+	if n < 0 {
+		mv.ScrollView.ScrollUp(-n)
+	} else if n > 0 {
+		mv.ScrollView.ScrollDown(n)
+	}
+}
+
+// PageHeight - the lines scrolled by a page: the height of the viewport, keeping a line in view
+func (mv *MainView) PageHeight() int {
+	if h := mv.ScrollView.GetViewport().Height - 1; h > 1 {
+		return h
+	}
+	return 1
+}
+
+// ScrollOffset - how far the output is scrolled down
+func (mv *MainView) ScrollOffset() int {
+	return -mv.OutputView.GetBounds().Y
+}
+
+// ScrollPercent - how far the output is scrolled, as a percentage. False if it all fits in the viewport
+func (mv *MainView) ScrollPercent() (int, bool) {
+	scrollable := mv.OutputView.GetBounds().Height - mv.ScrollView.GetViewport().Height
+	if scrollable <= 0 {
+		return 0, false
+	}
+	pct := mv.ScrollOffset() * 100 / scrollable
+	if pct > 100 {
+		pct = 100
+	}
+	return pct, true
+}
+
+// HeadingPathInView - the titles of the headings down to the section at the top of the viewport
+func (mv *MainView) HeadingPathInView() []string {
+	if mv.activeFile == nil || mv.activeFile.Document == nil || !mv.OutputView.ShowingNote() {
+		return nil
+	}
+	if n := mv.headingInView(); n != nil {
+		if doc := mv.activeFile.Document.FindHeading(n); doc != nil {
+			return doc.HeadingTitles()
+		}
+	}
+	if mv.activeDoc != nil {
+		return mv.activeDoc.HeadingTitles()
+	}
+	return nil
+}
+
+// VisibleLinks - the links drawn within the viewport, top to bottom
+func (mv *MainView) VisibleLinks() []htmlrender.LinkPosition {
+	top := mv.ScrollOffset()
+	bottom := top + mv.ScrollView.GetViewport().Height
+	res := make([]htmlrender.LinkPosition, 0)
+	for _, l := range mv.OutputView.Links() {
+		if l.Y >= top && l.Y < bottom {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
 func (mv *MainView) HandleKeyEvent(e *egg.KeyEvent) {
 	// switch e.Key {
 	// case egg.KeyUp: